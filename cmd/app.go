@@ -66,6 +66,18 @@ Generally Marct tries to stay compatible with Minecraft Launcher, but no warrant
 			}), 1)
 		}
 
+		settings, settingsErr := workDir.OpenSettings()
+		if settingsErr != nil {
+			return cli.Exit(locales.TranslateWith(&i18n.Message{
+				ID:    "app.error.settings-read-err",
+				Other: "Cannot read settings: {{ .Error }}",
+			}, map[string]string{
+				"Error": settingsErr.Error(),
+			}), 1)
+		}
+
+		configureNetwork(workDir, &settings.Settings)
+
 		ctx.Context = context.WithValue(ctx.Context, workDirKey, workDirPath)
 		ctx.Context = context.WithValue(ctx.Context, instanceKey, workDir)
 
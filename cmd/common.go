@@ -306,14 +306,16 @@ func WithMessage(v string) SelectProfileFlowOption {
 	}
 }
 
-func SelectProfileFlow(p *launcher.Profiles, options ...SelectProfileFlowOption) (*launcher.Profile, error) {
+// SelectProfileFlow provides a standard CLI flow for selecting a profile. It returns the selected profile together with
+// its identifier. Error will be wrapped as a cli.ExitCoder, so there's no need to handle it.
+func SelectProfileFlow(p *launcher.Profiles, options ...SelectProfileFlowOption) (string, *launcher.Profile, error) {
 	var o selectProfileFlowOptions
 	for _, opt := range options {
 		opt(&o)
 	}
 
 	if p == nil || len(p.Profiles) == 0 {
-		return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+		return "", nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
 			TemplateData: map[string]string{
 				"Command": strings.Join([]string{
 					app.Name,
@@ -329,9 +331,10 @@ func SelectProfileFlow(p *launcher.Profiles, options ...SelectProfileFlowOption)
 	}
 
 	if !o.SkipSelected && p.SelectedProfile != nil {
-		p, ok := p.Profiles[*p.SelectedProfile]
+		id := *p.SelectedProfile
+		p, ok := p.Profiles[id]
 		if ok {
-			return &p, nil
+			return id, &p, nil
 		}
 	}
 
@@ -399,7 +402,7 @@ func SelectProfileFlow(p *launcher.Profiles, options ...SelectProfileFlowOption)
 	}))
 
 	if err != nil {
-		return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+		return "", nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
 			TemplateData: map[string]string{
 				"Error": err.Error(),
 			},
@@ -410,10 +413,11 @@ func SelectProfileFlow(p *launcher.Profiles, options ...SelectProfileFlowOption)
 		}), 1)
 	}
 
-	s, ok := p.Profiles[variantMappings[selection]]
+	id := variantMappings[selection]
+	s, ok := p.Profiles[id]
 
 	if !ok {
-		return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+		return "", nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
 			TemplateData: map[string]string{
 				"Selection": selection,
 			},
@@ -424,7 +428,7 @@ func SelectProfileFlow(p *launcher.Profiles, options ...SelectProfileFlowOption)
 		}), 1)
 	}
 
-	return &s, nil
+	return id, &s, nil
 }
 
 // Regular Expression for checking the valid Minecraft username.
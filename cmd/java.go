@@ -14,7 +14,7 @@ var javaCommand = createCommand(&cli.Command{
 	}),
 	Description: locales.Translate(&i18n.Message{
 		ID:    "command.java.description",
-		Other: "This command allows you to manage Mojang JRE installations",
+		Other: "This command allows you to manage Mojang JRE installations and find other Java installations on the system",
 	}),
 })
 
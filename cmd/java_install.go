@@ -22,19 +22,48 @@ var javaInstallCommand = createCommand(&cli.Command{
 	}),
 	Description: locales.Translate(&i18n.Message{
 		ID:    "command.java-install.description",
-		Other: "Installs a Mojang JRE either interactively or by passed flag",
+		Other: "Installs a JRE either interactively or by passed flag, from Mojang or, if Mojang does not provide it for the system, from Adoptium",
 	}),
 	ArgsUsage: locales.Translate(&i18n.Message{
 		ID:    "command.java-install.args",
 		Other: "<type>",
 	}),
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name: "build",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.java-install.flag.build.usage",
+				Other: "Install exact build of the JRE and pin it, so that later installs and launches keep using it",
+			}),
+		},
+		&cli.BoolFlag{
+			Name: "unpin",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.java-install.flag.unpin.usage",
+				Other: "Remove the pin and install the most recent build of the JRE",
+			}),
+		},
+	},
 	Action: func(ctx *cli.Context) error {
 		workDir := ctx.Context.Value(instanceKey).(*launcher.Instance)
 
-		if ctx.IsSet("version") && ctx.IsSet("latest") {
+		if ctx.IsSet("build") && ctx.Bool("unpin") {
 			return cli.Exit(locales.Translate(&i18n.Message{
 				ID:    "command.java-install.error.incompatible-flags",
-				Other: "Both version and latest flags are provided, only one is allowed",
+				Other: "Both build and unpin flags are provided, only one is allowed",
+			}), 1)
+		}
+
+		settings, err := workDir.OpenSettings()
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.java-install.error.settings-read",
+					Other: "Cannot read your settings: {{ .Error }}",
+				},
 			}), 1)
 		}
 
@@ -47,130 +76,74 @@ var javaInstallCommand = createCommand(&cli.Command{
 
 		t := ctx.Args().First()
 
-		platforms, fetchErr := workDir.FetchJREs(false)
-		if fetchErr != nil || platforms == nil {
-			return cli.Exit(locales.Translate(&i18n.Message{
-				ID:    "command.java-install.error.fetch-failed",
-				Other: "Cannot retrieve a list of available JREs",
-			}), 1)
+		if len(t) == 0 {
+			var selectErr error
+			if t, selectErr = askJREComponent(workDir); selectErr != nil {
+				return selectErr
+			}
 		}
 
-		classifiers, selector := platforms.GetMatching()
+		var build string
 
-		if len(classifiers) == 0 {
+		switch {
+		case ctx.IsSet("build"):
+			build = ctx.String("build")
+		case !ctx.Bool("unpin"):
+			build = settings.JavaPin(t)
+		}
+
+		provider, err := workDir.JREProvider(t, settings.JRE)
+		if err != nil {
 			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
 				TemplateData: map[string]string{
-					"Selector": selector,
+					"Error": err.Error(),
 				},
 				DefaultMessage: &i18n.Message{
-					ID:    "command.java-install.error.no-jres-available",
-					Other: "No JREs available for the platform {{ .Selector }}",
+					ID:    "command.java-install.error.provider-failed",
+					Other: "Cannot choose where to install the JRE from: {{ .Error }}",
 				},
 			}), 1)
 		}
 
-		if len(t) == 0 {
-			var options []string
-			optionsMappings := map[string]string{}
-
-			for classifier, v := range classifiers {
-				mostRecent := v.MostRecent()
+		bar := newProgressBar()
+		installErr := workDir.InstallJRE(provider, t, build, bar.UpdateJRE)
+		bar.Done()
 
-				if mostRecent == nil {
-					continue
+		if installErr != nil {
+			if errors.Is(installErr, &launcher.JavaUnavailableError{Errno: launcher.ErrBuildUnavailable}) {
+				if builds := mojangJREBuilds(workDir, provider, t); len(builds) != 0 {
+					return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+						TemplateData: map[string]string{
+							"Type":   t,
+							"Build":  build,
+							"Builds": strings.Join(builds, ", "),
+						},
+						DefaultMessage: &i18n.Message{
+							ID:    "command.java-install.error.build-unavailable",
+							Other: "Build {{ .Build }} of {{ .Type }} is not available, available builds: {{ .Builds }}",
+						},
+					}), 1)
 				}
 
-				option := fmt.Sprintf("%s (%s)", classifier, mostRecent.Version.Name)
-				optionsMappings[option] = classifier
-				options = append(options, option)
-			}
-
-			sort.Strings(options)
-
-			var resp string
-
-			if surveyingErr := survey.AskOne(&survey.Select{
-				Message: locales.Translate(&i18n.Message{
-					ID:    "command.java-install.survey.type",
-					Other: "Select type of JRE to install",
-				}),
-				Options: options,
-			}, &resp); surveyingErr != nil {
-				return cli.Exit(locales.Translate(&i18n.Message{
-					ID:    "command.java-install.error.survey-fail-type",
-					Other: "Failed to read response for 'type'",
+				return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Type":     t,
+						"Build":    build,
+						"Provider": provider.Name(),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.java-install.error.build-unavailable-provider",
+						Other: "Build {{ .Build }} of {{ .Type }} is not available from {{ .Provider }}",
+					},
 				}), 1)
 			}
 
-			t = optionsMappings[resp]
-		}
-
-		if installErr := workDir.InstallJRE(*platforms, t); installErr != nil {
 			{
 				var v *launcher.PostValidationError
 				if errors.As(installErr, &v) {
-					buf := new(strings.Builder)
-
-					for i, object := range v.BadObjects {
-						if i != 0 {
-							buf.WriteByte('\n')
-						}
-
-						var objectType string
-						var objectState string
-
-						switch object.Type {
-						case java.TypeDir:
-							objectType = locales.Translate(&i18n.Message{
-								ID:    "command.java-install.error.post-validation.object-type.dir",
-								Other: "directory",
-							})
-						case java.TypeFile:
-							objectType = locales.Translate(&i18n.Message{
-								ID:    "command.java-install.error.post-validation.object-type.file",
-								Other: "file",
-							})
-						case java.TypeLink:
-							objectType = locales.Translate(&i18n.Message{
-								ID:    "command.java-install.error.post-validation.object-type.link",
-								Other: "link",
-							})
-						}
-
-						switch object.State {
-						case launcher.FileStateCorrupted:
-							objectState = locales.Translate(&i18n.Message{
-								ID:    "command.java-install.error.post-validation.object-state.corrupted",
-								Other: "corrupted",
-							})
-						case launcher.FileStateNotDownloaded:
-							objectState = locales.Translate(&i18n.Message{
-								ID:    "command.java-install.error.post-validation.object-state.not-downloaded",
-								Other: "does not exist",
-							})
-						default:
-							objectState = locales.Translate(&i18n.Message{
-								ID:    "command.java-install.error.post-validation.object-state.unknown",
-								Other: "unknown",
-							})
-						}
-
-						buf.WriteString(locales.TranslateUsing(&i18n.LocalizeConfig{
-							TemplateData: map[string]string{
-								"Type":  objectType,
-								"Path":  object.Destination,
-								"State": objectState,
-							},
-							DefaultMessage: &i18n.Message{
-								ID:    "command.java-install.error.install-failed-dir",
-								Other: "- {{ .Type }} {{ .Path }}: {{ .State }}",
-							},
-						}))
-					}
-
 					return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
 						TemplateData: map[string]string{
-							"List": buf.String(),
+							"List": formatJREObjects(v.BadObjects),
 						},
 						DefaultMessage: &i18n.Message{
 							ID:    "command.java-install.error.post-validation-failed",
@@ -191,10 +164,181 @@ var javaInstallCommand = createCommand(&cli.Command{
 			}), 1)
 		}
 
+		if ctx.IsSet("build") || ctx.Bool("unpin") {
+			settings.SetJavaPin(t, ctx.String("build"))
+
+			if err := settings.Save(); err != nil {
+				return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Error": err.Error(),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.java-install.error.settings-save",
+						Other: "JRE is installed, but the pin cannot be saved: {{ .Error }}",
+					},
+				}), 1)
+			}
+		}
+
 		return nil
 	},
 })
 
+// askJREComponent asks which runtime component to install. Components listed by Mojang for the current system are
+// offered with their most recent builds, if there are none, all components known to other providers are offered.
+func askJREComponent(workDir *launcher.Instance) (string, error) {
+	platforms, fetchErr := workDir.FetchJREs(false)
+	if fetchErr != nil || platforms == nil {
+		return "", cli.Exit(locales.Translate(&i18n.Message{
+			ID:    "command.java-install.error.fetch-failed",
+			Other: "Cannot retrieve a list of available JREs",
+		}), 1)
+	}
+
+	classifiers, selector := platforms.GetMatching()
+
+	var options []string
+	optionsMappings := map[string]string{}
+
+	for classifier, v := range classifiers {
+		mostRecent := v.MostRecent()
+
+		if mostRecent == nil {
+			continue
+		}
+
+		option := fmt.Sprintf("%s (%s)", classifier, mostRecent.Version.Name)
+		optionsMappings[option] = classifier
+		options = append(options, option)
+	}
+
+	if len(options) == 0 {
+		for _, component := range launcher.KnownJavaComponents() {
+			optionsMappings[component] = component
+			options = append(options, component)
+		}
+	}
+
+	if len(options) == 0 {
+		return "", cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Selector": selector,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.java-install.error.no-jres-available",
+				Other: "No JREs available for the platform {{ .Selector }}",
+			},
+		}), 1)
+	}
+
+	sort.Strings(options)
+
+	var resp string
+
+	if surveyingErr := survey.AskOne(&survey.Select{
+		Message: locales.Translate(&i18n.Message{
+			ID:    "command.java-install.survey.type",
+			Other: "Select type of JRE to install",
+		}),
+		Options: options,
+	}, &resp); surveyingErr != nil {
+		return "", cli.Exit(locales.Translate(&i18n.Message{
+			ID:    "command.java-install.error.survey-fail-type",
+			Other: "Failed to read response for 'type'",
+		}), 1)
+	}
+
+	return optionsMappings[resp], nil
+}
+
+// mojangJREBuilds returns sorted names of the builds of the component Mojang provides for the current system, or nil if
+// the runtime is installed from another provider.
+func mojangJREBuilds(workDir *launcher.Instance, provider launcher.JREProvider, component string) []string {
+	if provider.Name() != launcher.JREProviderMojang {
+		return nil
+	}
+
+	platforms, err := workDir.FetchJREs(false)
+	if err != nil || platforms == nil {
+		return nil
+	}
+
+	classifiers, _ := platforms.GetMatching()
+
+	var builds []string
+	for _, d := range classifiers[component] {
+		builds = append(builds, d.Version.Name)
+	}
+
+	sort.Strings(builds)
+
+	return builds
+}
+
+// formatJREObjects formats the list of runtime objects that are not ready, one per line.
+func formatJREObjects(objects []launcher.JREObject) string {
+	buf := new(strings.Builder)
+
+	for i, object := range objects {
+		if i != 0 {
+			buf.WriteByte('\n')
+		}
+
+		var objectType string
+		var objectState string
+
+		switch object.Type {
+		case java.TypeDir:
+			objectType = locales.Translate(&i18n.Message{
+				ID:    "command.java-install.error.post-validation.object-type.dir",
+				Other: "directory",
+			})
+		case java.TypeFile:
+			objectType = locales.Translate(&i18n.Message{
+				ID:    "command.java-install.error.post-validation.object-type.file",
+				Other: "file",
+			})
+		case java.TypeLink:
+			objectType = locales.Translate(&i18n.Message{
+				ID:    "command.java-install.error.post-validation.object-type.link",
+				Other: "link",
+			})
+		}
+
+		switch object.State {
+		case launcher.FileStateCorrupted:
+			objectState = locales.Translate(&i18n.Message{
+				ID:    "command.java-install.error.post-validation.object-state.corrupted",
+				Other: "corrupted",
+			})
+		case launcher.FileStateNotDownloaded:
+			objectState = locales.Translate(&i18n.Message{
+				ID:    "command.java-install.error.post-validation.object-state.not-downloaded",
+				Other: "does not exist",
+			})
+		default:
+			objectState = locales.Translate(&i18n.Message{
+				ID:    "command.java-install.error.post-validation.object-state.unknown",
+				Other: "unknown",
+			})
+		}
+
+		buf.WriteString(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Type":  objectType,
+				"Path":  object.Destination,
+				"State": objectState,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.java-install.error.install-failed-dir",
+				Other: "- {{ .Type }} {{ .Path }}: {{ .State }}",
+			},
+		}))
+	}
+
+	return buf.String()
+}
+
 func init() {
 	javaCommand.Subcommands = append(javaCommand.Subcommands, javaInstallCommand)
 }
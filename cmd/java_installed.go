@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var javaInstalledCommand = createCommand(&cli.Command{
+	Name: "installed",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.java-installed.usage",
+		Other: "List installed Java runtimes",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID:    "command.java-installed.description",
+		Other: "Lists Mojang JREs installed in the instance with their builds and size on disk",
+	}),
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		installed, err := instance.InstalledJREs()
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.java-installed.error.read-failed",
+					Other: "Cannot read installed runtimes: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		if len(installed) == 0 {
+			fmt.Println(locales.Translate(&i18n.Message{
+				ID:    "command.java-installed.none",
+				Other: "No runtimes are installed",
+			}))
+			return nil
+		}
+
+		settings, err := instance.OpenSettings()
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.java-installed.error.settings-read",
+					Other: "Cannot read your settings: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(tw, locales.Translate(&i18n.Message{
+			ID:    "command.java-installed.header",
+			Other: "COMPONENT\tSYSTEM\tBUILD\tPINNED\tSIZE",
+		}))
+
+		for _, j := range installed {
+			build := j.Version
+			if build == "" {
+				build = locales.Translate(&i18n.Message{
+					ID:    "command.java-installed.incomplete",
+					Other: "(incomplete)",
+				})
+			}
+
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				j.Component,
+				j.Selector,
+				build,
+				settings.JavaPin(j.Component),
+				formatSize(j.Size),
+			)
+		}
+
+		return tw.Flush()
+	},
+})
+
+// formatSize formats the size in bytes using binary units.
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	javaCommand.Subcommands = append(javaCommand.Subcommands, javaInstalledCommand)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var javaListCommand = createCommand(&cli.Command{
+	Name: "list",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.java-list.usage",
+		Other: "List Java installations found on the system",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID: "command.java-list.description",
+		Other: "Finds Java installations in JAVA_HOME, PATH, system JVM directory, SDKMAN!, ~/.jdks and runtimes of the" +
+			" official launcher, and prints their versions",
+	}),
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name: "verbose",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.java-list.flag.verbose.usage",
+				Other: "Print installations that could not be probed",
+			}),
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		found, errs := instance.DiscoverJava()
+
+		if ctx.Bool("verbose") {
+			for _, err := range errs {
+				println(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Error": err.Error(),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.java-list.warn.probe-failed",
+						Other: "Skipped: {{ .Error }}",
+					},
+				}))
+			}
+		}
+
+		if len(found) == 0 {
+			fmt.Println(locales.Translate(&i18n.Message{
+				ID:    "command.java-list.none-found",
+				Other: "No Java installations have been found",
+			}))
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(tw, locales.Translate(&i18n.Message{
+			ID:    "command.java-list.header",
+			Other: "MAJOR\tVERSION\tVENDOR\tARCH\tSOURCE\tPATH",
+		}))
+
+		for _, j := range found {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				strconv.Itoa(j.MajorVersion),
+				j.Version,
+				j.Vendor,
+				j.Arch,
+				j.Source,
+				j.Path,
+			)
+		}
+
+		return tw.Flush()
+	},
+})
+
+func init() {
+	javaCommand.Subcommands = append(javaCommand.Subcommands, javaListCommand)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var javaRepairCommand = createCommand(&cli.Command{
+	Name: "repair",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.java-repair.usage",
+		Other: "Repair installed Java runtime",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID:    "command.java-repair.description",
+		Other: "Hashes all files of the installed Mojang JRE and downloads again only those that are missing or corrupted",
+	}),
+	ArgsUsage: locales.Translate(&i18n.Message{
+		ID:    "command.java-repair.args-usage",
+		Other: "<component>",
+	}),
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		installation, err := openJREOrExit(ctx, instance)
+		if err != nil {
+			return err
+		}
+
+		bad, err := installation.Verify(true)
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.java-verify.error.verify-failed",
+					Other: "Cannot verify the runtime: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		if len(bad) == 0 {
+			fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Component": installation.Classifier,
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.java-repair.nothing-to-repair",
+					Other: "{{ .Component }} is intact, nothing to repair",
+				},
+			}))
+
+			return nil
+		}
+
+		fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Count": strconv.Itoa(len(bad)),
+				"List":  formatJREObjects(bad),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.java-repair.repairing",
+				Other: "Repairing {{ .Count }} objects:\n{{ .List }}",
+			},
+		}))
+
+		bar := newProgressBar()
+		installation.Progress = bar.UpdateJRE
+
+		err = installation.Repair()
+		bar.Done()
+
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.java-repair.error.repair-failed",
+					Other: "Repair failed: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		fmt.Println(locales.Translate(&i18n.Message{
+			ID:    "command.java-repair.repaired",
+			Other: "Runtime has been repaired",
+		}))
+
+		return nil
+	},
+})
+
+func init() {
+	javaCommand.Subcommands = append(javaCommand.Subcommands, javaRepairCommand)
+}
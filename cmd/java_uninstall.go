@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var javaUninstallCommand = createCommand(&cli.Command{
+	Name: "uninstall",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.java-uninstall.usage",
+		Other: "Remove installed Java runtime",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID:    "command.java-uninstall.description",
+		Other: "Removes Mojang JRE installed for the current system, unless a game running in background uses it",
+	}),
+	ArgsUsage: locales.Translate(&i18n.Message{
+		ID:    "command.java-uninstall.args-usage",
+		Other: "<component>",
+	}),
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		if ctx.NArg() != 1 {
+			return cli.Exit(locales.Translate(&i18n.Message{
+				ID:    "command.java.error.illegal-num-of-args",
+				Other: "Illegal number of arguments: expected only runtime component",
+			}), 1)
+		}
+
+		component := ctx.Args().First()
+
+		if err := instance.UninstallJRE(component); err != nil {
+			var inUseErr *launcher.JREInUseError
+
+			switch {
+			case errors.As(err, &inUseErr):
+				return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Component": component,
+						"Session":   inUseErr.SessionID,
+						"PID":       strconv.Itoa(inUseErr.PID),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.java-uninstall.error.in-use",
+						Other: "Runtime {{ .Component }} is used by session {{ .Session }} (PID {{ .PID }}), stop it first",
+					},
+				}), 1)
+			case errors.Is(err, &launcher.JavaUnavailableError{Errno: launcher.ErrNotInstalled}):
+				return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Component": component,
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.java.error.not-installed",
+						Other: "Runtime {{ .Component }} is not installed",
+					},
+				}), 1)
+			default:
+				return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Component": component,
+						"Error":     err.Error(),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.java-uninstall.error.uninstall-failed",
+						Other: "Cannot remove runtime {{ .Component }}: {{ .Error }}",
+					},
+				}), 1)
+			}
+		}
+
+		fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Component": component,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.java-uninstall.uninstalled",
+				Other: "Runtime {{ .Component }} has been removed",
+			},
+		}))
+
+		return nil
+	},
+})
+
+func init() {
+	javaCommand.Subcommands = append(javaCommand.Subcommands, javaUninstallCommand)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var javaVerifyCommand = createCommand(&cli.Command{
+	Name: "verify",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.java-verify.usage",
+		Other: "Verify installed Java runtime",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID: "command.java-verify.description",
+		Other: "Checks that files of the installed Mojang JRE are present and not modified. By default, files which size" +
+			" and modification time haven't changed since installation are not hashed",
+	}),
+	ArgsUsage: locales.Translate(&i18n.Message{
+		ID:    "command.java-verify.args-usage",
+		Other: "<component>",
+	}),
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name: "full",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.java-verify.flag.full.usage",
+				Other: "Hash all files instead of trusting unchanged size and modification time",
+			}),
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		installation, err := openJREOrExit(ctx, instance)
+		if err != nil {
+			return err
+		}
+
+		bad, err := installation.Verify(ctx.Bool("full"))
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.java-verify.error.verify-failed",
+					Other: "Cannot verify the runtime: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		if len(bad) != 0 {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Component": installation.Classifier,
+					"Count":     strconv.Itoa(len(bad)),
+					"List":      formatJREObjects(bad),
+				},
+				DefaultMessage: &i18n.Message{
+					ID: "command.java-verify.error.bad-objects",
+					Other: "{{ .Count }} objects of {{ .Component }} are missing or corrupted:\n{{ .List }}\n" +
+						"Use \"java repair {{ .Component }}\" to fix them.",
+				},
+			}), 1)
+		}
+
+		fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Component": installation.Classifier,
+				"Version":   installation.Descriptor.Version.Name,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.java-verify.ok",
+				Other: "{{ .Component }} ({{ .Version }}) is intact",
+			},
+		}))
+
+		return nil
+	},
+})
+
+// openJREOrExit opens the installed runtime specified by the first argument of the command.
+func openJREOrExit(ctx *cli.Context, instance *launcher.Instance) (*launcher.JREInstallation, error) {
+	if ctx.NArg() != 1 {
+		return nil, cli.Exit(locales.Translate(&i18n.Message{
+			ID:    "command.java.error.illegal-num-of-args",
+			Other: "Illegal number of arguments: expected only runtime component",
+		}), 1)
+	}
+
+	component := ctx.Args().First()
+
+	installation, err := instance.OpenJRE(component)
+	if err != nil {
+		if errors.Is(err, &launcher.JavaUnavailableError{Errno: launcher.ErrNotInstalled}) {
+			return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Component": component,
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.java.error.not-installed",
+					Other: "Runtime {{ .Component }} is not installed",
+				},
+			}), 1)
+		}
+
+		if errors.Is(err, &launcher.JREVerificationUnsupportedError{}) {
+			return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Component": component,
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.java.error.verification-unsupported",
+					Other: "Runtime {{ .Component }} cannot be verified, as its provider does not supply checksums of files; reinstall it instead",
+				},
+			}), 1)
+		}
+
+		return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Component": component,
+				"Error":     err.Error(),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.java.error.open-failed",
+				Other: "Cannot read installation of {{ .Component }}: {{ .Error }}",
+			},
+		}), 1)
+	}
+
+	return installation, nil
+}
+
+func init() {
+	javaCommand.Subcommands = append(javaCommand.Subcommands, javaVerifyCommand)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var killCommand = createCommand(&cli.Command{
+	Name: "kill",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.kill.usage",
+		Other: "Terminate game session running in background",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID:    "command.kill.description",
+		Other: "Asks the game session running in background to terminate, or kills it if --force is specified",
+	}),
+	ArgsUsage: locales.Translate(&i18n.Message{
+		ID:    "command.kill.args-usage",
+		Other: "<session identifier or PID>",
+	}),
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "force",
+			Aliases: []string{"f"},
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.kill.flag.force.usage",
+				Other: "Kill the game immediately without letting it save",
+			}),
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		s, err := findRunningSessionOrExit(ctx, instance)
+		if err != nil {
+			return err
+		}
+
+		if err := instance.KillSession(*s, ctx.Bool("force")); err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.kill.error.kill-failed",
+					Other: "Cannot terminate the session: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		return nil
+	},
+})
+
+func init() {
+	app.Commands = append(app.Commands, killCommand)
+}
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"errors"
-	"os/exec"
+	"fmt"
+	"math"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -12,11 +14,13 @@ import (
 	offlineAccount "github.com/brawaru/marct/offline/account"
 	offlineAuthFlow "github.com/brawaru/marct/offline/authflow"
 	"github.com/brawaru/marct/utils"
+	"github.com/brawaru/marct/utils/osfile"
 	"github.com/brawaru/marct/utils/pointers"
 	xboxAccount "github.com/brawaru/marct/xbox/account"
 	xboxAuthFlow "github.com/brawaru/marct/xbox/authflow"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
 )
 
 var launchCommand = createCommand(&cli.Command{
@@ -33,10 +37,94 @@ var launchCommand = createCommand(&cli.Command{
 		ID:    "command.launch.args-usage",
 		Other: "[profile id]",
 	}),
-	Flags: nil,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "background",
+			Aliases: []string{"b"},
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.background.usage",
+				Other: "Launch the game detached in background, use ps, kill and logs commands to manage it",
+			}),
+		},
+		&cli.BoolFlag{
+			Name: "force",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.force.usage",
+				Other: "Launch even if the game directory is used by another running session",
+			}),
+		},
+		&cli.BoolFlag{
+			Name: "ignore-java-version",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.ignore-java-version.usage",
+				Other: "Launch even if the Java set in the profile is older than the version requires",
+			}),
+		},
+		&cli.BoolFlag{
+			Name: "dry-run",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.dry-run.usage",
+				Other: "Print how the game would be launched without launching it",
+			}),
+		},
+		&cli.PathFlag{
+			Name: "export-script",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.export-script.usage",
+				Other: "Write a shell script launching the game to the file instead of launching it",
+			}),
+		},
+		&cli.StringFlag{
+			Name: "server",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.server.usage",
+				Other: "Join the server at address in host[:port] format once the game is launched",
+			}),
+		},
+		&cli.StringFlag{
+			Name: "world",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.world.usage",
+				Other: "Open the singleplayer world with the name of its directory once the game is launched",
+			}),
+		},
+		&cli.StringFlag{
+			Name: "log-level",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.log-level.usage",
+				Other: "Minimum level of the game output records to display (TRACE, DEBUG, INFO, WARN, ERROR, FATAL)",
+			}),
+		},
+		&cli.BoolFlag{
+			Name: "log-file",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.log-file.usage",
+				Other: "Write the game output to a log file in the logs directory",
+			}),
+		},
+		&cli.StringFlag{
+			Name: "log-json",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.log-json.usage",
+				Other: "Write the game output as JSON lines to the file, or to the standard output if \"-\" is specified",
+			}),
+		},
+		&cli.BoolFlag{
+			Name: "no-color",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.no-color.usage",
+				Other: "Do not color the game output",
+			}),
+		},
+	},
 	Action: func(ctx *cli.Context) error {
 		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
 
+		quickPlay, err := quickPlayFromFlags(ctx)
+		if err != nil {
+			return err
+		}
+
 		profiles, err := instance.ReadProfiles()
 		if err != nil {
 			if utils.DoesNotExist(err) {
@@ -59,10 +147,11 @@ var launchCommand = createCommand(&cli.Command{
 
 		// TODO: add -i option that prompts user to select profile to launch
 
+		var profileID string
 		var profile launcher.Profile
 		if ctx.NArg() == 0 {
 			if profiles.SelectedProfile == nil || ctx.Bool("i") {
-				p, err := SelectProfileFlow(profiles, WithMessage(locales.Translate(&i18n.Message{
+				id, p, err := SelectProfileFlow(profiles, WithMessage(locales.Translate(&i18n.Message{
 					ID:    "command.launch.prompt.select-profile",
 					Other: "Select profile to launch",
 				})))
@@ -71,6 +160,7 @@ var launchCommand = createCommand(&cli.Command{
 					return err
 				}
 
+				profileID = id
 				profile = *p
 			} else {
 				i := *profiles.SelectedProfile
@@ -87,6 +177,7 @@ var launchCommand = createCommand(&cli.Command{
 						},
 					}), 1)
 				}
+				profileID = i
 				profile = p
 			}
 		} else if ctx.NArg() == 1 {
@@ -103,6 +194,7 @@ var launchCommand = createCommand(&cli.Command{
 					},
 				}), 1)
 			}
+			profileID = i
 			profile = p
 		} else {
 			return cli.Exit(locales.Translate(&i18n.Message{
@@ -260,7 +352,7 @@ var launchCommand = createCommand(&cli.Command{
 			}), 1) // FIXME: translate error to message
 		}
 
-		if err := instance.DownloadVersion(*version); err != nil {
+		if err := downloadVersion(instance, *version); err != nil {
 			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
 				TemplateData: map[string]string{
 					"Error": err.Error(),
@@ -272,49 +364,115 @@ var launchCommand = createCommand(&cli.Command{
 			}), 1) // FIXME: translate error to message
 		}
 
-		if lr, err := instance.Launch(*version, launcher.LaunchOptions{
-			Background:    ctx.Bool("background"),
-			JavaPath:      pointers.DerefOrDefault(profile.JavaPath),
-			Resolution:    profile.Resolution,
-			Authorization: *selectedAccount.Authorization,
-			GameDirectory: filepath.Join(instance.Path, filepath.FromSlash(profile.GameDir)), // MCL compat: no sanitization
-			JavaArgs:      profile.JavaArgs,
-		}); err != nil {
+		settings, err := instance.OpenSettings()
+		if err != nil {
 			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
 				TemplateData: map[string]string{
 					"Error": err.Error(),
 				},
 				DefaultMessage: &i18n.Message{
-					ID:    "command.launch.error.launch-failed",
-					Other: "Cannot launch game: {{ .Error }}",
+					ID:    "command.launch.error.settings-read-failed",
+					Other: "Cannot read your settings: {{ .Error }}",
 				},
-			}), 1) // FIXME: translate error to message
-		} else {
-			if err := lr.Command.Wait(); err != nil {
-				var e *exec.ExitError
+			}), 1)
+		}
 
-				if errors.As(err, &e) && e.ExitCode() != 0 {
-					println(locales.TranslateUsing(&i18n.LocalizeConfig{
-						TemplateData: map[string]string{
-							"ExitCode": strconv.Itoa(e.ExitCode()),
-						},
-						DefaultMessage: &i18n.Message{
-							ID:    "command.launch.warn.non-zero-exit",
-							Other: "Game process exited with code {{ .ExitCode }}",
-						},
-					}))
-				}
+		if pointers.DerefOrDefault(profile.JavaPath) == "" {
+			component := launcher.JavaComponent(*version)
 
+			if err := instance.EnsureJRE(component, &settings.Settings, func(v string) {
+				fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Component": component,
+						"Version":   v,
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.launch.installing-jre",
+						Other: "Installing Java runtime {{ .Component }} ({{ .Version }}), please wait...",
+					},
+				}))
+			}); err != nil {
 				return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
 					TemplateData: map[string]string{
-						"Error": err.Error(),
+						"Component": component,
+						"Error":     err.Error(),
 					},
 					DefaultMessage: &i18n.Message{
-						ID:    "command.launch.error.wait-error",
-						Other: "Cannot wait for child process: {{ .Error }}",
+						ID:    "command.launch.error.jre-install-failed",
+						Other: "Cannot install Java runtime {{ .Component }}: {{ .Error }}",
 					},
 				}), 1)
 			}
+		}
+
+		profileSettings := settings.Profile(profileID)
+
+		options := launcher.LaunchOptions{
+			Background:        ctx.Bool("background"),
+			JavaPath:          pointers.DerefOrDefault(profile.JavaPath),
+			Resolution:        profile.Resolution,
+			Authorization:     *selectedAccount.Authorization,
+			GameDirectory:     filepath.Join(instance.Path, filepath.FromSlash(profile.GameDir)), // MCL compat: no sanitization
+			JavaArgs:          profile.JavaArgs,
+			ProfileID:         profileID,
+			QuickPlay:         quickPlay,
+			Wrapper:           profileSettings.Wrapper,
+			Env:               profileSettings.Env,
+			MemoryHint:        profileSettings.MemoryHint,
+			Force:             ctx.Bool("force"),
+			IgnoreJavaVersion: ctx.Bool("ignore-java-version"),
+		}
+
+		if options.JavaPath != "" && !options.IgnoreJavaVersion {
+			if err := checkProfileJava(instance, options.JavaPath, *version); err != nil {
+				return err
+			}
+		}
+
+		if ctx.Bool("dry-run") || ctx.String("export-script") != "" {
+			return launchPlanFlow(ctx, instance, *version, options)
+		}
+
+		if !options.Background {
+			sinks, err := createLogSinks(ctx, instance)
+			if err != nil {
+				return err
+			}
+
+			defer closeLogSinks(sinks)
+
+			options.LogSinks = sinks
+		}
+
+		if lr, err := instance.Launch(*version, options); err != nil {
+			var busyErr *launcher.GameDirectoryBusyError
+			if errors.As(err, &busyErr) {
+				return cli.Exit(translateGameDirectoryBusyError(busyErr), 1)
+			}
+
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.launch.error.launch-failed",
+					Other: "Cannot launch game: {{ .Error }}",
+				},
+			}), 1) // FIXME: translate error to message
+		} else if lr.Session != nil {
+			fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"ID":  lr.Session.ID,
+					"PID": strconv.Itoa(lr.Session.PID),
+					"Log": lr.Session.LogFile,
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.launch.started-in-background",
+					Other: "Game is running in background as session {{ .ID }} (PID {{ .PID }}), its output is written to {{ .Log }}",
+				},
+			}))
+		} else {
+			result, waitErr := lr.Supervisor.Wait()
 
 			if err := lr.Clean(); err != nil {
 				return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
@@ -327,12 +485,290 @@ var launchCommand = createCommand(&cli.Command{
 					},
 				}), 1)
 			}
+
+			if result == nil {
+				return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Error": waitErr.Error(),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.launch.error.wait-error",
+						Other: "Cannot wait for child process: {{ .Error }}",
+					},
+				}), 1)
+			}
+
+			if waitErr != nil {
+				println(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Error": waitErr.Error(),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.launch.warn.session-record-failed",
+						Other: "Session has not been recorded completely: {{ .Error }}",
+					},
+				}))
+			}
+
+			if result.Outcome != launcher.OutcomeNormalExit {
+				return cli.Exit(translateSessionOutcome(result), 1)
+			}
 		}
 
 		return nil
 	},
 })
 
+// quickPlayFromFlags returns the Quick Play target requested by the launch flags, or nil if none was requested.
+func quickPlayFromFlags(ctx *cli.Context) (*launcher.QuickPlay, error) {
+	server := ctx.String("server")
+	world := ctx.String("world")
+
+	switch {
+	case server != "" && world != "":
+		return nil, cli.Exit(locales.Translate(&i18n.Message{
+			ID:    "command.launch.error.quick-play-conflict",
+			Other: "Cannot join both server and world, specify only one of them",
+		}), 1)
+	case server != "":
+		if _, _, err := launcher.SplitServerAddress(server); err != nil {
+			return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.launch.error.invalid-server-address",
+					Other: "Invalid server address: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		return &launcher.QuickPlay{Type: launcher.QuickPlayMultiplayer, Target: server}, nil
+	case world != "":
+		return &launcher.QuickPlay{Type: launcher.QuickPlaySingleplayer, Target: world}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// createLogSinks creates sinks for the game output as requested by the launch flags.
+func createLogSinks(ctx *cli.Context, instance *launcher.Instance) ([]launcher.LogSink, error) {
+	var sinks []launcher.LogSink
+
+	jsonPath := ctx.String("log-json")
+
+	if jsonPath == "-" {
+		sinks = append(sinks, launcher.NewJSONLinesSink(os.Stdout))
+	} else {
+		color := !ctx.Bool("no-color") && term.IsTerminal(int(os.Stdout.Fd()))
+		sinks = append(sinks, launcher.NewTerminalSink(os.Stdout, color))
+
+		if jsonPath != "" {
+			f, err := osfile.New(jsonPath)
+			if err != nil {
+				closeLogSinks(sinks)
+				return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Error": err.Error(),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.launch.error.log-json-open-failed",
+						Other: "Cannot open JSON log file: {{ .Error }}",
+					},
+				}), 1)
+			}
+
+			sinks = append(sinks, launcher.NewJSONLinesSink(f))
+		}
+	}
+
+	if ctx.Bool("log-file") {
+		keep := launcher.SessionLogsKeep
+
+		// logs of the sessions running in background are still followed by the logs command
+		inUse, err := instance.RunningSessionLogs()
+		if err != nil {
+			keep = math.MaxInt // cannot tell which logs are in use, so none is deleted
+		}
+
+		s, err := launcher.NewSessionFileSink(instance.SessionLogsPath(), keep, inUse...)
+		if err != nil {
+			closeLogSinks(sinks)
+			return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.launch.error.log-file-open-failed",
+					Other: "Cannot create log file: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		sinks = append(sinks, s)
+	}
+
+	if ctx.IsSet("log-level") {
+		l, err := launcher.ParseLogLevel(ctx.String("log-level"))
+		if err != nil {
+			closeLogSinks(sinks)
+			return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Level": ctx.String("log-level"),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.launch.error.invalid-log-level",
+					Other: "Unknown log level \"{{ .Level }}\"",
+				},
+			}), 1)
+		}
+
+		for i, s := range sinks {
+			sinks[i] = launcher.FilterLevel(l, s)
+		}
+	}
+
+	return sinks, nil
+}
+
+// closeLogSinks closes all the sinks, printing a warning for those that fail to close.
+func closeLogSinks(sinks []launcher.LogSink) {
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			println(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.launch.warn.log-sink-close-failed",
+					Other: "Cannot close game output log: {{ .Error }}",
+				},
+			}))
+		}
+	}
+}
+
+// checkProfileJava checks that Java set in the profile can run the version, warning if it may not.
+func checkProfileJava(instance *launcher.Instance, path string, version launcher.Version) error {
+	java, err := instance.CheckJava(path, version)
+
+	var mismatchErr *launcher.JavaVersionMismatchError
+	if errors.As(err, &mismatchErr) {
+		return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Path":     mismatchErr.Path,
+				"Actual":   strconv.Itoa(mismatchErr.Actual),
+				"Required": strconv.Itoa(mismatchErr.Required),
+				"Version":  version.ID,
+			},
+			DefaultMessage: &i18n.Message{
+				ID: "command.launch.error.java-too-old",
+				Other: "Java {{ .Path }} is version {{ .Actual }}, but {{ .Version }} requires Java {{ .Required }} or newer." +
+					" Change Java in the profile or use --ignore-java-version to launch anyway.",
+			},
+		}), 1)
+	}
+
+	if err != nil {
+		return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Error": err.Error(),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.launch.error.java-probe-failed",
+				Other: "Cannot check Java set in the profile: {{ .Error }}",
+			},
+		}), 1)
+	}
+
+	if java.LegacyJavaMismatch(version) {
+		println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Path":    java.Path,
+				"Actual":  strconv.Itoa(java.MajorVersion),
+				"Version": version.ID,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.launch.warn.java-newer-than-legacy",
+				Other: "Java {{ .Path }} is version {{ .Actual }}, but {{ .Version }} is made for Java 8 and may fail to start with newer Java",
+			},
+		}))
+	}
+
+	return nil
+}
+
+// translateGameDirectoryBusyError returns a message explaining that the game directory is used by another session.
+func translateGameDirectoryBusyError(err *launcher.GameDirectoryBusyError) string {
+	session := err.SessionID
+	if session == "" {
+		session = locales.Translate(&i18n.Message{
+			ID:    "command.launch.error.game-directory-busy.starting-session",
+			Other: "that is still starting",
+		})
+	}
+
+	return locales.TranslateUsing(&i18n.LocalizeConfig{
+		TemplateData: map[string]string{
+			"Directory": err.Directory,
+			"Session":   session,
+			"PID":       strconv.Itoa(err.PID),
+		},
+		DefaultMessage: &i18n.Message{
+			ID: "command.launch.error.game-directory-busy",
+			Other: "Game directory {{ .Directory }} is used by another session {{ .Session }} (PID {{ .PID }})." +
+				" Use --force to launch anyway, which may corrupt your settings and worlds.",
+		},
+	})
+}
+
+// translateSessionOutcome returns a message describing why the session has ended abnormally.
+func translateSessionOutcome(r *launcher.SessionResult) string {
+	switch r.Outcome {
+	case launcher.OutcomeCrashed:
+		return locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"ExitCode":    strconv.Itoa(r.ExitCode),
+				"CrashReport": r.CrashReport,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.launch.outcome.crashed",
+				Other: "Game has crashed (exit code {{ .ExitCode }}). Crash report: {{ .CrashReport }}",
+			},
+		})
+	case launcher.OutcomeJVMFailure:
+		return locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"ExitCode": strconv.Itoa(r.ExitCode),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.launch.outcome.jvm-failure",
+				Other: "Java has failed to start the game (exit code {{ .ExitCode }}). Check the output above for details.",
+			},
+		})
+	case launcher.OutcomeKilled:
+		return locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Signal": r.Signal,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.launch.outcome.killed",
+				Other: "Game process was terminated by signal: {{ .Signal }}",
+			},
+		})
+	default:
+		return locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"ExitCode": strconv.Itoa(r.ExitCode),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.launch.warn.non-zero-exit",
+				Other: "Game process exited with code {{ .ExitCode }}",
+			},
+		})
+	}
+}
+
 func init() {
 	app.Commands = append(app.Commands, launchCommand)
 }
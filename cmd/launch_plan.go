@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/brawaru/marct/utils"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+// printLaunchPlan prints the plan with secrets redacted.
+func printLaunchPlan(plan *launcher.LaunchPlan) {
+	p := plan.Redacted()
+
+	section := func(title string, values []string) {
+		fmt.Println(title)
+		for _, v := range values {
+			fmt.Println("  " + v)
+		}
+	}
+
+	fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+		TemplateData: map[string]string{
+			"Path": p.JavaPath,
+		},
+		DefaultMessage: &i18n.Message{
+			ID:    "command.launch.plan.java",
+			Other: "Java: {{ .Path }}",
+		},
+	}))
+
+	if len(p.Wrapper) != 0 {
+		fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Command": strings.Join(p.Wrapper, " "),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.launch.plan.wrapper",
+				Other: "Wrapper: {{ .Command }}",
+			},
+		}))
+	}
+
+	fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+		TemplateData: map[string]string{
+			"Path": p.WorkingDirectory,
+		},
+		DefaultMessage: &i18n.Message{
+			ID:    "command.launch.plan.working-directory",
+			Other: "Working directory: {{ .Path }}",
+		},
+	}))
+
+	fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+		TemplateData: map[string]string{
+			"Path": p.NativesDirectory,
+		},
+		DefaultMessage: &i18n.Message{
+			ID:    "command.launch.plan.natives-directory",
+			Other: "Natives directory: {{ .Path }}",
+		},
+	}))
+
+	if m := p.Memory; m != nil {
+		printMemoryPolicy(m)
+	}
+
+	section(locales.Translate(&i18n.Message{
+		ID:    "command.launch.plan.class-path",
+		Other: "Class path:",
+	}), p.ClassPath)
+
+	if len(p.Env) != 0 {
+		section(locales.Translate(&i18n.Message{
+			ID:    "command.launch.plan.env",
+			Other: "Environment:",
+		}), p.Env)
+	}
+
+	section(locales.Translate(&i18n.Message{
+		ID:    "command.launch.plan.argv",
+		Other: "Arguments:",
+	}), p.Argv)
+}
+
+// printMemoryPolicy explains how the heap size and garbage collector have been chosen.
+func printMemoryPolicy(m *launcher.MemoryPolicy) {
+	hint := string(m.Hint)
+	if hint == "" {
+		hint = string(launcher.MemoryHintVanilla)
+	}
+
+	fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+		TemplateData: map[string]string{
+			"Xms":  strconv.FormatUint(m.XmsMiB, 10),
+			"Xmx":  strconv.FormatUint(m.XmxMiB, 10),
+			"GC":   string(m.GC),
+			"Hint": hint,
+		},
+		DefaultMessage: &i18n.Message{
+			ID:    "command.launch.plan.memory",
+			Other: "Memory: {{ .Xms }}-{{ .Xmx }} MiB heap, {{ .GC }} garbage collector ({{ .Hint }})",
+		},
+	}))
+
+	if m.Memory == nil {
+		fmt.Println(locales.Translate(&i18n.Message{
+			ID:    "command.launch.plan.memory.unknown",
+			Other: "  System memory is unknown, heap is not limited by it",
+		}))
+	} else {
+		fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Total":     strconv.FormatUint(m.Memory.Total/(1024*1024), 10),
+				"Available": strconv.FormatUint(m.Memory.Available/(1024*1024), 10),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.launch.plan.memory.system",
+				Other: "  System memory: {{ .Total }} MiB total, {{ .Available }} MiB available",
+			},
+		}))
+	}
+
+	if m.Capped {
+		fmt.Println(locales.Translate(&i18n.Message{
+			ID:    "command.launch.plan.memory.capped",
+			Other: "  Heap has been reduced to leave enough memory for the system",
+		}))
+	}
+}
+
+// exportLaunchScript prepares the plan and writes a shell script starting the game to the file. The script contains the
+// access token, so the file is only made accessible to its owner.
+func exportLaunchScript(instance *launcher.Instance, plan *launcher.LaunchPlan, name string) error {
+	if err := instance.PrepareLaunch(plan); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0700)
+	if err != nil {
+		return err
+	}
+
+	defer utils.DClose(f)
+
+	if err := f.Chmod(0700); err != nil {
+		return err
+	}
+
+	return plan.WriteScript(f)
+}
+
+// launchPlanFlow handles --dry-run and --export-script flags of the launch command, which do not start the game.
+func launchPlanFlow(ctx *cli.Context, instance *launcher.Instance, version launcher.Version, options launcher.LaunchOptions) error {
+	plan, err := instance.PlanLaunch(version, options)
+	if err != nil {
+		return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Error": err.Error(),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.launch.error.plan-failed",
+				Other: "Cannot plan the launch: {{ .Error }}",
+			},
+		}), 1)
+	}
+
+	if ctx.Bool("dry-run") {
+		printLaunchPlan(plan)
+	}
+
+	if name := ctx.String("export-script"); name != "" {
+		if err := exportLaunchScript(instance, plan, name); err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.launch.error.export-script-failed",
+					Other: "Cannot export launch script: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Path":    name,
+				"Natives": plan.NativesDirectory,
+			},
+			DefaultMessage: &i18n.Message{
+				ID: "command.launch.script-exported",
+				Other: "Launch script written to {{ .Path }}. It contains your access token, do not share it." +
+					" Script uses natives cached in {{ .Natives }}, which are removed by \"utils gc-natives --all\".",
+			},
+		}))
+	}
+
+	return nil
+}
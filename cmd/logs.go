@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/brawaru/marct/utils"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// logsPollInterval is how often the log file is checked for new output when following it.
+const logsPollInterval = 500 * time.Millisecond
+
+var logsCommand = createCommand(&cli.Command{
+	Name: "logs",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.logs.usage",
+		Other: "Print output of game session running in background",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID:    "command.logs.description",
+		Other: "Prints output of the game session running in background, optionally following it until the game exits",
+	}),
+	ArgsUsage: locales.Translate(&i18n.Message{
+		ID:    "command.logs.args-usage",
+		Other: "<session identifier or PID>",
+	}),
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "follow",
+			Aliases: []string{"f"},
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.logs.flag.follow.usage",
+				Other: "Keep printing new output until the game exits",
+			}),
+		},
+		&cli.BoolFlag{
+			Name: "no-color",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.launch.flag.no-color.usage",
+				Other: "Do not color the game output",
+			}),
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		s, err := findRunningSessionOrExit(ctx, instance)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(s.LogFile)
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.logs.error.open-log",
+					Other: "Cannot open the session log: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		defer utils.DClose(f)
+
+		color := !ctx.Bool("no-color") && term.IsTerminal(int(os.Stdout.Fd()))
+		sink := launcher.NewTerminalSink(os.Stdout, color)
+
+		w := &launcher.Log4JWriter{
+			Consumer: func(event launcher.Log4JEvent) {
+				_ = sink.Write(event.Record())
+			},
+			LineConsumer: func(line string) {
+				_ = sink.Write(launcher.LogRecord{Message: line, Raw: true})
+			},
+		}
+
+		for {
+			// checked before reading, so the output written right before the exit is not lost
+			alive := s.Alive()
+
+			if _, err := io.Copy(w, f); err != nil {
+				return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Error": err.Error(),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "command.logs.error.read-log",
+						Other: "Cannot read the session log: {{ .Error }}",
+					},
+				}), 1)
+			}
+
+			if !ctx.Bool("follow") || !alive {
+				break
+			}
+
+			time.Sleep(logsPollInterval)
+		}
+
+		return w.Flush()
+	},
+})
+
+func init() {
+	app.Commands = append(app.Commands, logsCommand)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/99designs/keyring"
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/brawaru/marct/network"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// proxyPassword reads the password of the proxy user from the keyring. If the password has not been saved yet, the
+// warning is printed and empty password is returned, so that it can still be saved with proxy-login command.
+func proxyPassword(instance *launcher.Instance, proxy launcher.ProxySettings) (string, error) {
+	k, err := keyringOpenFlow(instance)
+	if err != nil {
+		return "", err
+	}
+
+	item, err := k.Get(proxy.KeyringKey())
+	if err != nil {
+		if !errors.Is(err, keyring.ErrKeyNotFound) {
+			return "", errors.New(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "app.error.proxy-password-read-err",
+					Other: "Cannot read proxy password from your keyring: {{ .Error }}",
+				},
+			}))
+		}
+
+		println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"User": proxy.User,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "app.warn.proxy-password-missing",
+				Other: "Password of the proxy user {{ .User }} is not saved, use `marct utils proxy-login` to save it",
+			},
+		}))
+
+		return "", nil
+	}
+
+	return string(item.Data), nil
+}
+
+// configureNetwork applies network settings to all requests made by the commands. Transport is only configured before
+// the first request, so that commands working offline neither open the keyring for the proxy password, nor fail on
+// unreadable certificates.
+func configureNetwork(instance *launcher.Instance, settings *launcher.Settings) {
+	network.SetMirrors(settings.Network.MirrorRules())
+
+	network.ConfigureTransportLazily(func() error {
+		return configureTransport(instance, settings)
+	})
+}
+
+// configureTransport applies proxy and certificate settings to the transport shared by all requests.
+func configureTransport(instance *launcher.Instance, settings *launcher.Settings) error {
+	password := ""
+	if settings.Network.Proxy.KeyringKey() != "" {
+		p, err := proxyPassword(instance, settings.Network.Proxy)
+		if err != nil {
+			return err
+		}
+
+		password = p
+	}
+
+	o, err := settings.Network.TransportOptions(password)
+	if err == nil {
+		err = network.ConfigureTransport(o)
+	}
+
+	if err != nil {
+		return errors.New(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Error": err.Error(),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "app.error.network-settings-err",
+				Other: "Cannot apply network settings: {{ .Error }}",
+			},
+		}))
+	}
+
+	return nil
+}
@@ -88,6 +88,24 @@ var profileCreateCommand = createCommand(&cli.Command{
 			}),
 			DefaultText: "auto",
 		},
+		&cli.StringFlag{
+			Name: "wrapper",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.profile-create.args.wrapper",
+				Other: "Command to run the game through (e.g. gamemoderun)",
+			}),
+		},
+		&cli.StringSliceFlag{
+			Name: "env",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.profile-create.args.env",
+				Other: "Environment variable for the game in KEY=VALUE format, can be repeated",
+			}),
+		},
+		&cli.StringFlag{
+			Name:  "memory-hint",
+			Usage: memoryHintFlagUsage(),
+		},
 		&cli.BoolFlag{
 			Name: "overwrite",
 			Usage: locales.Translate(&i18n.Message{
@@ -140,6 +158,22 @@ var profileCreateCommand = createCommand(&cli.Command{
 			}
 		}
 
+		env, err := parseEnvFlag(ctx.StringSlice("env"))
+		if err != nil {
+			return err
+		}
+
+		memoryHint, err := parseMemoryHintFlag(ctx.String("memory-hint"))
+		if err != nil {
+			return err
+		}
+
+		profileSettings := launcher.ProfileSettings{
+			Wrapper:    ctx.String("wrapper"),
+			Env:        env,
+			MemoryHint: memoryHint,
+		}
+
 		defaults := ctx.Bool("defaults")
 
 		var profile launcher.Profile
@@ -388,6 +422,20 @@ var profileCreateCommand = createCommand(&cli.Command{
 			}
 		}
 
+		if ctx.IsSet("java-path") {
+			s := ctx.String("java-path")
+			profile.JavaPath = &s
+		} else if defaults {
+			profile.JavaPath = nil
+		} else {
+			javaPath, err := askJavaPath(workDir)
+			if err != nil {
+				return err
+			}
+
+			profile.JavaPath = javaPath
+		}
+
 		if ctx.IsSet("java-args") {
 			s := ctx.String("java-args")
 			profile.JavaArgs = &s
@@ -403,7 +451,7 @@ var profileCreateCommand = createCommand(&cli.Command{
 				}),
 				Help: locales.Translate(&i18n.Message{
 					ID:    "command.profile-create.survey.jvm-args.help",
-					Other: "Arguments for Java Virtual Machine. Use default value if you don't know what these mean.",
+					Other: "Arguments for Java Virtual Machine. Use default value if you don't know what these mean, then heap size and garbage collector are chosen automatically.",
 				}),
 				Default: launcher.DefaultJVMArgs,
 			}, &s); err != nil {
@@ -440,6 +488,13 @@ var profileCreateCommand = createCommand(&cli.Command{
 			}), 1)
 		}
 
+		// overwritten profile must not inherit settings of the previous one
+		if !profileSettings.IsEmpty() || overwrite {
+			return updateProfileSettings(workDir, id, func(p *launcher.ProfileSettings) {
+				*p = profileSettings
+			})
+		}
+
 		return nil
 	},
 })
@@ -524,6 +579,89 @@ func translateResolutionParseError(err error) string {
 	})
 }
 
+// askJavaPath asks the user to choose Java for the profile among the installations found on the system. It returns nil
+// if the user has chosen Mojang runtime, which is picked by the version at launch.
+func askJavaPath(instance *launcher.Instance) (*string, error) {
+	found, _ := instance.DiscoverJava()
+
+	mojangOption := locales.Translate(&i18n.Message{
+		ID:    "command.profile-create.survey.java-path.option.mojang",
+		Other: "Mojang runtime required by the version (recommended)",
+	})
+
+	otherOption := locales.Translate(&i18n.Message{
+		ID:    "command.profile-create.survey.java-path.option.other",
+		Other: "Other…",
+	})
+
+	options := []string{mojangOption}
+	paths := map[string]string{}
+
+	for _, j := range found {
+		option := locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Version": j.Version,
+				"Vendor":  j.Vendor,
+				"Source":  string(j.Source),
+				"Path":    j.Path,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.profile-create.survey.java-path.option.found",
+				Other: "Java {{ .Version }} by {{ .Vendor }} ({{ .Source }}): {{ .Path }}",
+			},
+		})
+
+		options = append(options, option)
+		paths[option] = j.Path
+	}
+
+	options = append(options, otherOption)
+
+	var selected string
+
+	if err := survey.AskOne(&survey.Select{
+		Message: locales.Translate(&i18n.Message{
+			ID:    "command.profile-create.survey.java-path",
+			Other: "Java to run the game with",
+		}),
+		Options: options,
+		Default: mojangOption,
+	}, &selected); err != nil {
+		return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Name": "java-path",
+			},
+			DefaultMessage: msgSurveyFail,
+		}), 1)
+	}
+
+	switch selected {
+	case mojangOption:
+		return nil, nil
+	case otherOption:
+		var s string
+
+		if err := survey.AskOne(&survey.Input{
+			Message: locales.Translate(&i18n.Message{
+				ID:    "command.profile-create.survey.java-path.other",
+				Other: "Java executable path",
+			}),
+		}, &s, survey.WithValidator(survey.Required)); err != nil {
+			return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Name": "java-path",
+				},
+				DefaultMessage: msgSurveyFail,
+			}), 1)
+		}
+
+		return &s, nil
+	default:
+		s := paths[selected]
+		return &s, nil
+	}
+}
+
 func init() {
 	profileCommand.Subcommands = append(profileCommand.Subcommands, profileCreateCommand)
 }
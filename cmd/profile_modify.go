@@ -73,6 +73,31 @@ var profileModifyCommand = createCommand(&cli.Command{
 			}),
 			DefaultText: "auto",
 		},
+		&cli.StringFlag{
+			Name: "wrapper",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.profile-modify.args.wrapper",
+				Other: "Command to run the game through (e.g. gamemoderun), empty value removes it",
+			}),
+		},
+		&cli.StringSliceFlag{
+			Name: "env",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.profile-modify.args.env",
+				Other: "Environment variable for the game in KEY=VALUE format, can be repeated",
+			}),
+		},
+		&cli.StringFlag{
+			Name:  "memory-hint",
+			Usage: memoryHintFlagUsage(),
+		},
+		&cli.StringSliceFlag{
+			Name: "unset-env",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.profile-modify.args.unset-env",
+				Other: "Name of the environment variable to remove, can be repeated",
+			}),
+		},
 	},
 	Action: func(ctx *cli.Context) error {
 		profileID := ctx.Args().First()
@@ -85,6 +110,7 @@ var profileModifyCommand = createCommand(&cli.Command{
 			}), 1)
 		}
 
+		workDir := ctx.Context.Value(instanceKey).(*launcher.Instance)
 		profiles := ctx.Context.Value(profilesKey).(*launcher.Profiles)
 
 		profile, profileExists := profiles.Profiles[profileID]
@@ -94,10 +120,23 @@ var profileModifyCommand = createCommand(&cli.Command{
 				TemplateData: map[string]string{
 					"ID": profileID,
 				},
-				DefaultMessage: nil,
+				DefaultMessage: &i18n.Message{
+					ID:    "command.profile-modify.error.profile-not-found",
+					Other: "Profile with identifier \"{{ .ID }}\" not found",
+				},
 			}), 1)
 		}
 
+		env, err := parseEnvFlag(ctx.StringSlice("env"))
+		if err != nil {
+			return err
+		}
+
+		memoryHint, err := parseMemoryHintFlag(ctx.String("memory-hint"))
+		if err != nil {
+			return err
+		}
+
 		// name, version, icon, path, jvm-args, java-path, resolution
 
 		if ctx.IsSet("name") {
@@ -136,10 +175,48 @@ var profileModifyCommand = createCommand(&cli.Command{
 			profile.Resolution = resolution
 		}
 
+		profiles.Profiles[profileID] = profile
+
+		if err := workDir.WriteProfiles(profiles); err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.profile-modify.error.profiles-write",
+					Other: "Cannot save profiles: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		if ctx.IsSet("wrapper") || ctx.IsSet("env") || ctx.IsSet("unset-env") || ctx.IsSet("memory-hint") {
+			return updateProfileSettings(workDir, profileID, func(p *launcher.ProfileSettings) {
+				if ctx.IsSet("wrapper") {
+					p.Wrapper = ctx.String("wrapper")
+				}
+
+				if ctx.IsSet("memory-hint") {
+					p.MemoryHint = memoryHint
+				}
+
+				for _, k := range ctx.StringSlice("unset-env") {
+					delete(p.Env, k)
+				}
+
+				if len(env) != 0 && p.Env == nil {
+					p.Env = map[string]string{}
+				}
+
+				for k, v := range env {
+					p.Env[k] = v
+				}
+			})
+		}
+
 		return nil
 	},
 })
 
 func init() {
-	app.Commands = append(app.Commands, profileModifyCommand)
+	profileCommand.Subcommands = append(profileCommand.Subcommands, profileModifyCommand)
 }
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+// parseEnvFlag parses values of the env flag in KEY=VALUE format into the map.
+func parseEnvFlag(values []string) (map[string]string, error) {
+	env := map[string]string{}
+
+	for _, v := range values {
+		k, val, ok := strings.Cut(v, "=")
+		if !ok || k == "" || strings.ContainsAny(k, " \t") {
+			return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Value": v,
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.profile.error.invalid-env",
+					Other: "Invalid environment variable \"{{ .Value }}\", expected KEY=VALUE format",
+				},
+			}), 1)
+		}
+
+		env[k] = val
+	}
+
+	return env, nil
+}
+
+// memoryHintFlagUsage returns usage of the memory-hint flag listing all known hints.
+func memoryHintFlagUsage() string {
+	hints := make([]string, len(launcher.MemoryHints))
+	for i, h := range launcher.MemoryHints {
+		hints[i] = string(h)
+	}
+
+	return locales.TranslateUsing(&i18n.LocalizeConfig{
+		TemplateData: map[string]string{
+			"Hints": strings.Join(hints, ", "),
+		},
+		DefaultMessage: &i18n.Message{
+			ID:    "command.profile.flag.memory-hint.usage",
+			Other: "How demanding the game is, used to choose heap size when JVM arguments are not set ({{ .Hints }})",
+		},
+	})
+}
+
+// parseMemoryHintFlag parses value of the memory-hint flag, empty value is returned as is to unset the hint.
+func parseMemoryHintFlag(value string) (launcher.MemoryHint, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	h, err := launcher.ParseMemoryHint(value)
+	if err != nil {
+		return "", cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Value": value,
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.profile.error.invalid-memory-hint",
+				Other: "Unknown memory hint \"{{ .Value }}\"",
+			},
+		}), 1)
+	}
+
+	return h, nil
+}
+
+// updateProfileSettings reads the settings, applies update to marct-specific settings of the profile and saves them.
+func updateProfileSettings(instance *launcher.Instance, id string, update func(p *launcher.ProfileSettings)) error {
+	s, err := instance.OpenSettings()
+	if err != nil {
+		return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Error": err.Error(),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.profile.error.settings-read",
+				Other: "Cannot read your settings: {{ .Error }}",
+			},
+		}), 1)
+	}
+
+	p := s.Profile(id)
+	update(&p)
+	s.SetProfile(id, p)
+
+	if err := s.Save(); err != nil {
+		return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Error": err.Error(),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.profile.error.settings-save",
+				Other: "Cannot save your settings: {{ .Error }}",
+			},
+		}), 1)
+	}
+
+	return nil
+}
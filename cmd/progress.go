@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/launcher/download"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/term"
+)
+
+// progressBarMaxWidth is the maximum width of the bar itself, not including the counters.
+const progressBarMaxWidth = 40
+
+// progressRedrawInterval limits how often the progress is redrawn, as downloads report it on every written chunk.
+const progressRedrawInterval = 100 * time.Millisecond
+
+// progressMaxFiles is the maximum number of files being downloaded that are displayed under the total progress.
+const progressMaxFiles = download.DefaultConcurrency
+
+// renderBar renders the bar of the width filled proportionally to done out of total.
+func renderBar(done uint64, total uint64, width int) string {
+	filled := width
+	if total != 0 {
+		filled = int(uint64(width) * done / total)
+	}
+
+	if filled > width {
+		filled = width
+	}
+
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", width-filled) + "]"
+}
+
+// progressLine renders the bar followed by the counters, fitting it into the terminal columns if they are known.
+func progressLine(fd int, done uint64, total uint64, counters string) string {
+	width := progressBarMaxWidth
+	if cols, _, err := term.GetSize(fd); err == nil && cols-len(counters)-3 < width {
+		width = cols - len(counters) - 3
+	}
+
+	if width <= 0 {
+		return counters
+	}
+
+	return renderBar(done, total, width) + " " + counters
+}
+
+// fitLine truncates the line to the terminal columns, if they are known.
+func fitLine(fd int, line string) string {
+	if cols, _, err := term.GetSize(fd); err == nil && cols > 0 && len(line) >= cols {
+		return line[:cols-1]
+	}
+
+	return line
+}
+
+// formatFilesProgress formats the number of files and bytes done out of total using the message.
+func formatFilesProgress(message *i18n.Message, filesDone int, filesTotal int, bytesDone uint64, bytesTotal uint64) string {
+	return locales.TranslateUsing(&i18n.LocalizeConfig{
+		TemplateData: map[string]string{
+			"FilesDone":  strconv.Itoa(filesDone),
+			"FilesTotal": strconv.Itoa(filesTotal),
+			"BytesDone":  formatSize(int64(bytesDone)),
+			"BytesTotal": formatSize(int64(bytesTotal)),
+		},
+		DefaultMessage: message,
+	})
+}
+
+// progressBar renders download progress on a single line of the terminal, redrawing it on each update. If the output is
+// not a terminal, nothing is rendered.
+type progressBar struct {
+	out     io.Writer
+	fd      int
+	enabled bool
+	drawn   int       // Length of the last drawn line, to clear what's left of it.
+	drawnAt time.Time // When the line has been drawn last time.
+}
+
+func newProgressBar() *progressBar {
+	fd := int(os.Stdout.Fd())
+
+	return &progressBar{
+		out:     os.Stdout,
+		fd:      fd,
+		enabled: term.IsTerminal(fd),
+	}
+}
+
+// UpdateJRE redraws the bar with the progress of the runtime installation.
+func (b *progressBar) UpdateJRE(p launcher.JREProgress) {
+	if !b.enabled || (p.FilesDone != p.FilesTotal && time.Since(b.drawnAt) < progressRedrawInterval) {
+		return
+	}
+
+	counters := formatFilesProgress(&i18n.Message{
+		ID:    "command.java.progress",
+		Other: "{{ .FilesDone }}/{{ .FilesTotal }} files, {{ .BytesDone }}/{{ .BytesTotal }}",
+	}, p.FilesDone, p.FilesTotal, p.BytesDone, p.BytesTotal)
+
+	b.draw(progressLine(b.fd, p.BytesDone, p.BytesTotal, counters))
+}
+
+// draw draws the line over the one drawn last time.
+func (b *progressBar) draw(line string) {
+	padding := ""
+	if b.drawn > len(line) {
+		padding = strings.Repeat(" ", b.drawn-len(line))
+	}
+
+	_, _ = fmt.Fprint(b.out, "\r"+line+padding)
+	b.drawn = len(line)
+	b.drawnAt = time.Now()
+}
+
+// Done moves to the next line if the bar has been drawn, so that further output is not printed over it.
+func (b *progressBar) Done() {
+	if b.drawn != 0 {
+		_, _ = fmt.Fprintln(b.out)
+		b.drawn = 0
+	}
+}
+
+// downloadDisplay renders the progress of the download manager as the line with the total progress, followed by a line
+// for each file being downloaded, redrawing them in place. If the output is not a terminal, nothing is rendered. If the
+// terminal does not process escape sequences, only the total progress is rendered.
+type downloadDisplay struct {
+	out     io.Writer
+	fd      int
+	enabled bool
+	bar     *progressBar            // Renders the total progress if lines cannot be redrawn in place, nil otherwise.
+	active  []download.FileProgress // Files being downloaded, in order they have started.
+	last    download.Progress       // Last reported progress.
+	lines   int                     // Number of lines drawn last time.
+	drawnAt time.Time               // When the lines have been drawn last time.
+}
+
+func newDownloadDisplay() *downloadDisplay {
+	fd := int(os.Stdout.Fd())
+
+	d := &downloadDisplay{
+		out:     os.Stdout,
+		fd:      fd,
+		enabled: term.IsTerminal(fd),
+	}
+
+	if d.enabled && !enableVirtualTerminal(fd) {
+		d.bar = newProgressBar()
+	}
+
+	return d
+}
+
+// track updates the list of files being downloaded with the file that has progressed.
+func (d *downloadDisplay) track(f download.FileProgress) {
+	for i, a := range d.active {
+		if a.Destination != f.Destination {
+			continue
+		}
+
+		if f.Done {
+			d.active = append(d.active[:i], d.active[i+1:]...)
+		} else {
+			d.active[i] = f
+		}
+
+		return
+	}
+
+	if !f.Done {
+		d.active = append(d.active, f)
+	}
+}
+
+// Update records the progress and redraws the display, unless it has been redrawn just now.
+func (d *downloadDisplay) Update(p download.Progress) {
+	if !d.enabled {
+		return
+	}
+
+	if p.File != nil {
+		d.track(*p.File)
+	}
+
+	d.last = p
+
+	if p.FilesDone != p.FilesTotal && time.Since(d.drawnAt) < progressRedrawInterval {
+		return
+	}
+
+	d.draw()
+}
+
+func (d *downloadDisplay) draw() {
+	p := d.last
+
+	counters := formatFilesProgress(&i18n.Message{
+		ID:    "command.download.progress",
+		Other: "{{ .FilesDone }}/{{ .FilesTotal }} files, {{ .BytesDone }}/{{ .BytesTotal }}",
+	}, p.FilesDone, p.FilesTotal, p.BytesDone, p.BytesTotal)
+	if p.ETA > 0 {
+		counters += locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"ETA": p.ETA.Round(time.Second).String(),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.download.progress.eta",
+				Other: ", {{ .ETA }} left",
+			},
+		})
+	}
+
+	if d.bar != nil {
+		d.bar.draw(progressLine(d.fd, p.BytesDone, p.BytesTotal, counters))
+		d.drawnAt = d.bar.drawnAt
+		return
+	}
+
+	lines := []string{progressLine(d.fd, p.BytesDone, p.BytesTotal, counters)}
+
+	for i, f := range d.active {
+		if i == progressMaxFiles {
+			break
+		}
+
+		size := formatSize(int64(f.BytesDone))
+		if f.BytesTotal != 0 {
+			size += "/" + formatSize(int64(f.BytesTotal))
+		}
+
+		lines = append(lines, fitLine(d.fd, "  "+filepath.Base(f.Destination)+" "+size))
+	}
+
+	// lines left from the last time are cleared, but kept, so the display does not jump
+	for len(lines) < d.lines {
+		lines = append(lines, "")
+	}
+
+	var b strings.Builder
+
+	if d.lines != 0 {
+		// move to the beginning of the first line drawn last time
+		_, _ = fmt.Fprintf(&b, "\x1b[%dF", d.lines)
+	}
+
+	for _, l := range lines {
+		b.WriteString("\x1b[2K" + l + "\n")
+	}
+
+	_, _ = io.WriteString(d.out, b.String())
+
+	d.lines = len(lines)
+	d.drawnAt = time.Now()
+}
+
+// Done draws the final progress without the files, so that further output is printed below it.
+func (d *downloadDisplay) Done() {
+	if d.bar != nil {
+		if d.bar.drawn != 0 {
+			d.draw()
+		}
+
+		d.bar.Done()
+		return
+	}
+
+	if d.enabled && d.lines != 0 {
+		d.active = nil
+		d.draw()
+	}
+}
+
+// downloadVersion downloads files of the version, displaying the progress.
+func downloadVersion(w *launcher.Instance, version launcher.Version) error {
+	m := download.NewManager(download.DefaultConcurrency)
+	display := newDownloadDisplay()
+
+	unsubscribe := m.Subscribe(display.Update)
+	defer unsubscribe()
+
+	err := w.DownloadVersion(version, m)
+	display.Done()
+
+	return err
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var psCommand = createCommand(&cli.Command{
+	Name: "ps",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.ps.usage",
+		Other: "List game sessions running in background",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID:    "command.ps.description",
+		Other: "Lists game sessions launched in background that are still running",
+	}),
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		sessions, err := instance.ReadRunningSessions()
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.ps.error.read-sessions",
+					Other: "Cannot read running sessions: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		// sessions running in foreground are managed by the launcher that started them
+		background := sessions[:0]
+		for _, s := range sessions {
+			if !s.Foreground {
+				background = append(background, s)
+			}
+		}
+		sessions = background
+
+		if len(sessions) == 0 {
+			fmt.Println(locales.Translate(&i18n.Message{
+				ID:    "command.ps.no-sessions",
+				Other: "No sessions are running",
+			}))
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(tw, locales.Translate(&i18n.Message{
+			ID:    "command.ps.header",
+			Other: "SESSION\tPID\tPROFILE\tVERSION\tACCOUNT\tUPTIME",
+		}))
+
+		for _, s := range sessions {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				s.ID,
+				strconv.Itoa(s.PID),
+				s.ProfileID,
+				s.VersionID,
+				s.Account,
+				time.Since(s.StartedAt).Round(time.Second),
+			)
+		}
+
+		return tw.Flush()
+	},
+})
+
+// findRunningSessionOrExit finds the running session specified by the first argument of the command.
+func findRunningSessionOrExit(ctx *cli.Context, instance *launcher.Instance) (*launcher.RunningSession, error) {
+	if ctx.NArg() != 1 {
+		return nil, cli.Exit(locales.Translate(&i18n.Message{
+			ID:    "command.session.error.illegal-num-of-args",
+			Other: "Illegal number of arguments: expected only session identifier or PID",
+		}), 1)
+	}
+
+	s, err := instance.FindRunningSession(ctx.Args().First())
+	if err != nil {
+		return nil, cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Error": err.Error(),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.session.error.find-session",
+				Other: "Cannot find the session: {{ .Error }}",
+			},
+		}), 1)
+	}
+
+	return s, nil
+}
+
+func init() {
+	app.Commands = append(app.Commands, psCommand)
+}
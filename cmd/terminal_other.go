@@ -0,0 +1,9 @@
+//go:build !windows
+
+package cmd
+
+// enableVirtualTerminal enables processing of escape sequences by the terminal, reporting whether they are supported.
+// Terminals other than Windows console process them already.
+func enableVirtualTerminal(_ int) bool {
+	return true
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package cmd
+
+import "golang.org/x/sys/windows"
+
+// enableVirtualTerminal enables processing of escape sequences by the console, reporting whether they are supported.
+// Consoles before Windows 10 print them as is.
+func enableVirtualTerminal(fd int) bool {
+	h := windows.Handle(fd)
+
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+
+	return windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var utilsCacheCommand = createCommand(&cli.Command{
+	Name: "cache",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.utils-cache.usage",
+		Other: "Manage cached manifests and indexes",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID: "command.utils-cache.description",
+		Other: "Manifests, runtime and asset indexes, and responses of third-party APIs are cached and only" +
+			" re-downloaded once they have changed. This command allows you to inspect and clear this cache.",
+	}),
+})
+
+func init() {
+	utilsCommand.Subcommands = append(utilsCommand.Subcommands, utilsCacheCommand)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var utilsCacheClearCommand = createCommand(&cli.Command{
+	Name: "clear",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.utils-cache-clear.usage",
+		Other: "Remove cached files",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID:    "command.utils-cache-clear.description",
+		Other: "Removes cached files, so that they are downloaded anew once needed. Asset indexes are kept unless" +
+			" requested, as the game cannot be launched offline without them",
+	}),
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name: "asset-indexes",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.utils-cache-clear.args.asset-indexes",
+				Other: "Remove asset indexes as well",
+			}),
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		removed, err := instance.ClearCache(ctx.Bool("asset-indexes"))
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Count": strconv.Itoa(len(removed)),
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.utils-cache-clear.error.clear-failed",
+					Other: "Cannot clear the cache after removing {{ .Count }} files: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Count": strconv.Itoa(len(removed)),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.utils-cache-clear.cleared",
+				Other: "Removed {{ .Count }} cached files",
+			},
+		}))
+
+		return nil
+	},
+})
+
+func init() {
+	utilsCacheCommand.Subcommands = append(utilsCacheCommand.Subcommands, utilsCacheClearCommand)
+}
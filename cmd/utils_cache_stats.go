@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var utilsCacheStatsCommand = createCommand(&cli.Command{
+	Name: "stats",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.utils-cache-stats.usage",
+		Other: "List cached files",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID:    "command.utils-cache-stats.description",
+		Other: "Lists cached files with their size and time since they were last fetched or confirmed to be fresh",
+	}),
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		files, err := instance.CachedFiles()
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.utils-cache-stats.error.read-failed",
+					Other: "Cannot read the cache: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		if len(files) == 0 {
+			fmt.Println(locales.Translate(&i18n.Message{
+				ID:    "command.utils-cache-stats.empty",
+				Other: "Cache is empty",
+			}))
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(tw, locales.Translate(&i18n.Message{
+			ID:    "command.utils-cache-stats.header",
+			Other: "FILE\tSIZE\tAGE",
+		}))
+
+		var total int64
+
+		for _, f := range files {
+			name := f.Name
+			if rel, err := filepath.Rel(instance.Path, f.Name); err == nil {
+				name = rel
+			}
+
+			age := "-"
+			if !f.Fetched.IsZero() {
+				age = time.Since(f.Fetched).Round(time.Second).String()
+			}
+
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", name, formatSize(f.Size), age)
+
+			total += f.Size
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]string{
+				"Count": strconv.Itoa(len(files)),
+				"Size":  formatSize(total),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.utils-cache-stats.total",
+				Other: "{{ .Count }} files, {{ .Size }} in total",
+			},
+		}))
+
+		return nil
+	},
+})
+
+func init() {
+	utilsCacheCommand.Subcommands = append(utilsCacheCommand.Subcommands, utilsCacheStatsCommand)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var utilsGCNativesCommand = createCommand(&cli.Command{
+	Name: "gc-natives",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.utils-gc-natives.usage",
+		Other: "Remove unused natives directories",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID: "command.utils-gc-natives.description",
+		Other: "Removes natives directories left by older runs and abandoned extractions." +
+			" With --all, cached natives not used by any running game are removed too.",
+	}),
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name: "all",
+			Usage: locales.Translate(&i18n.Message{
+				ID:    "command.utils-gc-natives.flag.all.usage",
+				Other: "Also remove cached natives that are not used by any running game",
+			}),
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		workDir := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		removed, err := workDir.GCNatives(ctx.Bool("all"))
+
+		for _, r := range removed {
+			fmt.Println(r)
+		}
+
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.utils-gc-natives.error.gc-failed",
+					Other: "Cannot remove unused natives: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		fmt.Println(locales.TranslateUsing(&i18n.LocalizeConfig{
+			TemplateData: map[string]int{
+				"Count": len(removed),
+			},
+			DefaultMessage: &i18n.Message{
+				ID:    "command.utils-gc-natives.removed",
+				Other: "Removed {{ .Count }} directories",
+			},
+		}))
+
+		return nil
+	},
+})
+
+func init() {
+	utilsCommand.Subcommands = append(utilsCommand.Subcommands, utilsGCNativesCommand)
+}
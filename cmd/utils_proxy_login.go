@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/99designs/keyring"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/brawaru/marct/launcher"
+	"github.com/brawaru/marct/locales"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/urfave/cli/v2"
+)
+
+var utilsProxyLoginCommand = createCommand(&cli.Command{
+	Name: "proxy-login",
+	Usage: locales.Translate(&i18n.Message{
+		ID:    "command.utils-proxy-login.usage",
+		Other: "Save the password of the proxy user",
+	}),
+	Description: locales.Translate(&i18n.Message{
+		ID: "command.utils-proxy-login.description",
+		Other: "Asks for the password of the user set in network.proxy.user setting and saves it to your keyring," +
+			" so that requests can be sent through the proxy that requires authentication.",
+	}),
+	Action: func(ctx *cli.Context) error {
+		instance := ctx.Context.Value(instanceKey).(*launcher.Instance)
+
+		settings, err := instance.OpenSettings()
+		if err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.utils-proxy-login.error.settings-read-failed",
+					Other: "Cannot read settings: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		proxy := settings.Network.Proxy
+
+		key := proxy.KeyringKey()
+		if key == "" {
+			return cli.Exit(locales.Translate(&i18n.Message{
+				ID:    "command.utils-proxy-login.error.no-proxy-user",
+				Other: "Proxy URL and user must be set in network.proxy settings first",
+			}), 1)
+		}
+
+		var password string
+
+		if err := survey.AskOne(&survey.Password{
+			Message: locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"User": proxy.User,
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.utils-proxy-login.prompt.password",
+					Other: "Password of the proxy user {{ .User }}",
+				},
+			}),
+		}, &password); err != nil {
+			return err
+		}
+
+		k, err := keyringOpenFlow(instance)
+		if err != nil {
+			return err
+		}
+
+		if err := k.Set(keyring.Item{
+			Key:  key,
+			Data: []byte(password),
+		}); err != nil {
+			return cli.Exit(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "command.utils-proxy-login.error.save-failed",
+					Other: "Cannot save the password to your keyring: {{ .Error }}",
+				},
+			}), 1)
+		}
+
+		fmt.Println(locales.Translate(&i18n.Message{
+			ID:    "command.utils-proxy-login.saved",
+			Other: "Password saved",
+		}))
+
+		return nil
+	},
+})
+
+func init() {
+	utilsCommand.Subcommands = append(utilsCommand.Subcommands, utilsProxyLoginCommand)
+}
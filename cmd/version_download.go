@@ -132,7 +132,7 @@ var versionInstallCommand = createCommand(&cli.Command{
 			}), 1)
 		}
 
-		versionDlErr := workDir.DownloadVersion(*version)
+		versionDlErr := downloadVersion(workDir, *version)
 
 		if versionDlErr != nil {
 			{
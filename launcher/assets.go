@@ -1,13 +1,12 @@
 package launcher
 
 import (
+	"crypto/sha1"
 	"path/filepath"
 
-	"github.com/brawaru/marct/globstate"
 	"github.com/brawaru/marct/launcher/download"
-	"github.com/brawaru/marct/locales"
-	"github.com/brawaru/marct/utils/terrgroup"
-	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/brawaru/marct/network"
+	"github.com/brawaru/marct/validfile"
 )
 
 func (w *Instance) AssetIndexPath(id string) string {
@@ -29,10 +28,39 @@ func (w *Instance) DefaultAssetsObjectResolver() AssetPathResolver {
 	}
 }
 
+// DownloadAssetIndex makes sure the asset index matches the descriptor, fetching it otherwise.
 func (w *Instance) DownloadAssetIndex(descriptor AssetIndexDescriptor) error {
 	dest := w.AssetIndexPath(descriptor.ID)
 
-	if err := download.FromURL(descriptor.URL, dest, download.WithSHA1(descriptor.SHA1)); err != nil {
+	if err := validfile.ValidateFileHex(dest, sha1.New(), descriptor.SHA1); err == nil {
+		return nil
+	}
+
+	// index URLs are content-addressed, so the index cached for another URL is not requested conditionally
+	updated, err := network.FetchCached(descriptor.URL, dest, 0, network.WithRetries())
+	if err != nil {
+		return err
+	}
+
+	err = validfile.ValidateFileHex(dest, sha1.New(), descriptor.SHA1)
+	if err == nil {
+		return nil
+	}
+
+	// must not be renewed by the next conditional request
+	_ = network.RemoveCached(dest)
+
+	if updated {
+		return err
+	}
+
+	// cached index has been damaged locally, while the server confirmed that it has not changed
+	if _, err := network.FetchCached(descriptor.URL, dest, 0, network.WithRetries()); err != nil {
+		return err
+	}
+
+	if err := validfile.ValidateFileHex(dest, sha1.New(), descriptor.SHA1); err != nil {
+		_ = network.RemoveCached(dest)
 		return err
 	}
 
@@ -45,38 +73,31 @@ func (w *Instance) ReadAssetIndex(id string) (i *AssetIndex, err error) {
 	return
 }
 
-func (w *Instance) DownloadAssets(index AssetIndex) error {
+// assetDownloads returns downloads of all objects in the asset index.
+func (w *Instance) assetDownloads(index AssetIndex) ([]*download.Download, error) {
 	op := w.AssetsObjectsPath()
-	g, _ := terrgroup.New(8)
-
-	for n, a := range index.Objects {
-		// Clone variables, so they don't change in async execution later
-		name := n
-		asset := a
-
-		g.Go(func() error {
-			p := filepath.Join(op, filepath.FromSlash(asset.Path()))
-
-			if err := download.FromURL(asset.URL(), p, download.WithSHA1(asset.Hash)); err != nil {
-				return err
-			}
-
-			if globstate.VerboseLogs {
-				println(locales.TranslateUsing(&i18n.LocalizeConfig{
-					TemplateData: map[string]string{
-						"Asset": name,
-						"ID":    asset.Hash,
-					},
-					DefaultMessage: &i18n.Message{
-						ID:    "log.verbose.downloaded-asset",
-						Other: "downloaded asset {{ .ID }} ({{ .Asset }})",
-					},
-				}))
-			}
-
-			return nil
-		})
+
+	downloads := make([]*download.Download, 0, len(index.Objects))
+
+	for _, asset := range index.Objects {
+		p := filepath.Join(op, filepath.FromSlash(asset.Path()))
+
+		d, err := download.NewURL(asset.URL(), p, download.WithSHA1(asset.Hash), download.WithSize(uint64(asset.Size)))
+		if err != nil {
+			return nil, err
+		}
+
+		downloads = append(downloads, d)
+	}
+
+	return downloads, nil
+}
+
+func (w *Instance) DownloadAssets(index AssetIndex) error {
+	downloads, err := w.assetDownloads(index)
+	if err != nil {
+		return err
 	}
 
-	return g.Wait()
+	return download.Run(downloads)
 }
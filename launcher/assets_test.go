@@ -1,10 +1,16 @@
 package launcher
 
 import (
+	"crypto/sha1"
 	_ "embed"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
 	"github.com/brawaru/marct/utils"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 //go:embed test_assets/assets_1.6.json
@@ -26,3 +32,41 @@ func TestParsing(t *testing.T) {
 
 	assert.Equal(t, object.URL(), "https://resources.download.minecraft.net/0d/0d000710b71ca9aafabd8f587768431d0b560b32")
 }
+
+func TestDownloadAssetIndex(t *testing.T) {
+	index := []byte(`{"objects":{}}`)
+	sum := sha1.Sum(index)
+
+	conditional := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write(index)
+	}))
+	defer srv.Close()
+
+	w := &Instance{Path: t.TempDir()}
+	descriptor := AssetIndexDescriptor{ID: "1.18", Download: Download{URL: srv.URL + "/1.18.json", SHA1: hex.EncodeToString(sum[:])}}
+
+	if !assert.NoError(t, w.DownloadAssetIndex(descriptor)) {
+		return
+	}
+
+	// damaged index is requested conditionally first, but fetched anew once the server confirms it has not changed
+	if !assert.NoError(t, os.WriteFile(w.AssetIndexPath(descriptor.ID), []byte("{}"), 0644)) {
+		return
+	}
+
+	if assert.NoError(t, w.DownloadAssetIndex(descriptor)) {
+		assert.Equal(t, 1, conditional)
+
+		b, _ := os.ReadFile(w.AssetIndexPath(descriptor.ID))
+		assert.Equal(t, index, b)
+	}
+}
@@ -0,0 +1,54 @@
+package launcher
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/brawaru/marct/network"
+)
+
+func (w *Instance) httpCachePath() string {
+	return filepath.Join(w.Path, filepath.FromSlash(httpCachePath))
+}
+
+func (w *Instance) adoptiumCachePath() string {
+	return filepath.Join(w.httpCachePath(), "adoptium")
+}
+
+// cacheDirs returns directories containing the responses cached with network.FetchCached. Asset indexes are only
+// included if assetIndexes is true.
+func (w *Instance) cacheDirs(assetIndexes bool) []string {
+	dirs := []string{
+		filepath.Dir(filepath.Join(w.Path, filepath.FromSlash(versionsManifestPath))),
+		w.jreRuntimesPath(),
+		w.adoptiumCachePath(),
+	}
+
+	if assetIndexes {
+		dirs = append(dirs, filepath.Join(w.Path, filepath.FromSlash(assetIndexesPath)))
+	}
+
+	return dirs
+}
+
+// CachedFiles lists the cached responses of manifests, indexes and third-party APIs.
+func (w *Instance) CachedFiles() ([]network.CachedFile, error) {
+	return network.CachedFiles(w.cacheDirs(true)...)
+}
+
+// ClearCache removes cached responses, so that they are fetched anew once needed. Asset indexes are needed to launch
+// the game and cannot be fetched again offline, so they are only removed if assetIndexes is true. Returns the files
+// removed before an error has occurred, if any.
+func (w *Instance) ClearCache(assetIndexes bool) (removed []network.CachedFile, err error) {
+	files, err := network.CachedFiles(w.cacheDirs(assetIndexes)...)
+
+	for _, f := range files {
+		if rmErr := network.RemoveCached(f.Name); rmErr != nil {
+			return removed, fmt.Errorf("remove %q: %w", f.Name, rmErr)
+		}
+
+		removed = append(removed, f)
+	}
+
+	return removed, err
+}
@@ -0,0 +1,13 @@
+package launcher
+
+import "time"
+
+const (
+	// Path where responses of third-party APIs are cached.
+	httpCachePath = "marct_cache"
+)
+
+const (
+	// Time after which the cached responses of Adoptium API are checked for changes.
+	adoptiumResponseTTL = time.Hour
+)
@@ -0,0 +1,41 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClearCache(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+
+	manifest := filepath.Join(w.Path, filepath.FromSlash(versionsManifestPath))
+	index := w.AssetIndexPath("1.18")
+
+	for _, name := range []string{manifest, index} {
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0755)) ||
+			!assert.NoError(t, os.WriteFile(name, []byte("{}"), 0644)) ||
+			!assert.NoError(t, os.WriteFile(name+".httpcache", []byte("{}"), 0644)) {
+			return
+		}
+	}
+
+	files, err := w.CachedFiles()
+	if assert.NoError(t, err) {
+		assert.Len(t, files, 2)
+	}
+
+	removed, err := w.ClearCache(false)
+	if assert.NoError(t, err) && assert.Len(t, removed, 1) {
+		assert.Equal(t, manifest, removed[0].Name)
+		assert.FileExists(t, index, "asset index is needed to launch offline")
+	}
+
+	removed, err = w.ClearCache(true)
+	if assert.NoError(t, err) && assert.Len(t, removed, 1) {
+		assert.Equal(t, index, removed[0].Name)
+		assert.NoFileExists(t, index)
+	}
+}
@@ -52,7 +52,7 @@ const (
 	//
 	// - .version - a text file containing that JRE version.
 	//
-	// - {classifier}.sha1 - a special text file containing hash sums. // TODO: write a parser for that file
+	// - {classifier}.sha1 - a text file containing hash sums and modification times of the files, see ParseJREIndex.
 	//
 	// - {classifier} - a folder containing the files of that JRE.
 	runtimesPath = "runtime"
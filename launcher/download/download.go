@@ -1,27 +1,38 @@
 package download
 
 import (
+	"context"
 	"crypto/md5"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 
+	"github.com/brawaru/marct/globstate"
 	"github.com/brawaru/marct/network"
+	"github.com/brawaru/marct/network/contdl"
 	"github.com/brawaru/marct/utils"
 	"github.com/brawaru/marct/utils/reflutils"
 	"github.com/brawaru/marct/validfile"
 )
 
-type Validator func() error
+// Validator checks whether the file with the name is a valid copy of the artifact.
+type Validator func(name string) error
 
 type Download struct {
 	DownloadURL *url.URL    // URL from where artifact is being downloaded
 	Destination string      // Where must this artifact be downloaded
 	Validators  []Validator // Validators to check the downloaded artifact.
+	Size        uint64      // Expected size of the artifact used to report progress, 0 if unknown.
+
+	progress network.ProgressFunc // Called as the artifact is being written, set by Manager.
 }
 
 func retrieveRemoteHash(retrievalUrl string) ([]byte, error) {
@@ -30,33 +41,38 @@ func retrieveRemoteHash(retrievalUrl string) ([]byte, error) {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	resp, reqErr := network.PerformRequest(req, network.WithRetries())
+	resp, reqErr := network.PerformRequest(req, network.WithRetries(), network.WithStatusCheck())
 	if reqErr != nil {
 		return nil, fmt.Errorf("request: %w", reqErr)
 	}
 
 	defer utils.DClose(resp.Body)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request not successful, got %v %s", resp.StatusCode, resp.Status)
-	}
-
 	buf, decodeErr := io.ReadAll(hex.NewDecoder(resp.Body))
 
 	if decodeErr != nil {
 		return nil, fmt.Errorf("hash decode err: %w", decodeErr)
 	}
 
-	fmt.Printf("hash retrieval %v: %x\n", retrievalUrl, buf)
+	if globstate.VerboseLogs {
+		fmt.Printf("hash retrieval %v: %x\n", retrievalUrl, buf)
+	}
 
 	return buf, nil
 }
 
+// Validate checks whether the artifact at the destination is valid.
 func (d *Download) Validate() error {
-	fmt.Printf("validating %s\n", d.Destination)
+	return d.validate(d.Destination)
+}
+
+func (d *Download) validate(name string) error {
+	if globstate.VerboseLogs {
+		fmt.Printf("validating %s\n", name)
+	}
 
 	for _, v := range d.Validators {
-		if err := v(); err != nil {
+		if err := v(name); err != nil {
 			return err
 		}
 	}
@@ -64,40 +80,180 @@ func (d *Download) Validate() error {
 	return nil
 }
 
+// resumableThreshold is the size of the artifact starting from which it is downloaded in a way that can be resumed after
+// interruption, even by the next run.
+const resumableThreshold = 8 << 20 // 8 MiB
+
+// resumeMarkerSuffix is the suffix of the file next to the temporary file, which records what is being downloaded to it.
+const resumeMarkerSuffix = ".resume"
+
+// resumeMarker records the download written to the temporary file, so that it can be resumed by the next run.
+type resumeMarker struct {
+	URL       string `json:"url"`       // URL the artifact is downloaded from.
+	Validator string `json:"validator"` // Entity tag or modification date of the artifact being downloaded.
+}
+
+func (d *Download) markerName() string {
+	return network.PartName(d.Destination) + resumeMarkerSuffix
+}
+
+// readMarker reads the resume marker, ok is false if there is no marker for the same URL.
+func (d *Download) readMarker() (m resumeMarker, ok bool) {
+	b, err := os.ReadFile(d.markerName())
+	if err != nil {
+		return
+	}
+
+	if err := json.Unmarshal(b, &m); err != nil {
+		return
+	}
+
+	return m, m.URL == d.DownloadURL.String() && m.Validator != ""
+}
+
+func (d *Download) writeMarker(validator string) {
+	b, err := json.Marshal(resumeMarker{URL: d.DownloadURL.String(), Validator: validator})
+	if err == nil {
+		err = os.WriteFile(d.markerName(), b, 0644)
+	}
+
+	if err != nil && globstate.VerboseLogs {
+		fmt.Printf("cannot write resume marker for %s: %s\n", d.Destination, err)
+	}
+}
+
+// resumable checks whether the artifact is large enough to be downloaded in a resumable way.
+func (d *Download) resumable() bool {
+	return d.Size >= resumableThreshold
+}
+
+// download writes the artifact to the temporary file, which is validated and only then moved to the destination, so
+// that the destination never contains a partially written or invalid file.
 func (d *Download) download() error {
-	_, err := network.Download(d.DownloadURL.String(), d.Destination)
+	if d.resumable() {
+		return d.downloadResumable()
+	}
+
+	part := network.PartName(d.Destination)
+
+	// temporary file may be left from the download that has been interrupted
+	d.discardPart()
+
+	if _, err := network.DownloadFile(d.DownloadURL.String(), part, d.progress, network.WithRetries()); err != nil {
+		removePart(part)
+		return err
+	}
+
+	return d.commitPart()
+}
+
+// downloadResumable is like download, but continues the download left in the temporary file by the previous run, and
+// keeps the temporary file if the download is interrupted.
+func (d *Download) downloadResumable() error {
+	part := network.PartName(d.Destination)
+
+	options := []contdl.Option{
+		contdl.WithProgress(d.progress),
+		contdl.WithResponseHook(func(resp *http.Response) {
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+
+			if v := contdl.Validator(resp); v != "" && resp.Header.Get("Accept-Ranges") == "bytes" {
+				d.writeMarker(v)
+			} else {
+				removePart(d.markerName())
+			}
+		}),
+	}
+
+	if m, ok := d.readMarker(); ok {
+		if globstate.VerboseLogs {
+			fmt.Printf("resuming download of %s\n", d.Destination)
+		}
 
+		options = append(options, contdl.WithResume(m.Validator))
+	} else {
+		d.discardPart()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(part), os.ModePerm); err != nil {
+		return fmt.Errorf("mkdir %q: %w", filepath.Dir(part), err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, d.DownloadURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	r, err := contdl.NewResumeableDownload(req, part, context.Background(), options...)
 	if err != nil {
+		return fmt.Errorf("open %q: %w", part, err)
+	}
+
+	err = r.Start()
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		// without the marker the next run cannot tell what has been written
+		if _, ok := d.readMarker(); !ok {
+			d.discardPart()
+		}
+
 		return err
 	}
 
+	return d.commitPart()
+}
+
+// commitPart validates the temporary file and moves it to the destination, or removes it if it's invalid.
+func (d *Download) commitPart() error {
+	part := network.PartName(d.Destination)
+
+	if err := d.validate(part); err != nil {
+		d.discardPart()
+		return err
+	}
+
+	if err := os.Rename(part, d.Destination); err != nil {
+		d.discardPart()
+		return fmt.Errorf("rename %q: %w", part, err)
+	}
+
+	removePart(d.markerName())
+
 	return nil
 }
 
-func (d *Download) Download() error {
-	shouldDownload := false
+// discardPart removes the temporary file and its resume marker.
+func (d *Download) discardPart() {
+	removePart(network.PartName(d.Destination))
+	removePart(d.markerName())
+}
 
+// removePart removes the temporary file left from the failed or interrupted download.
+func removePart(part string) {
+	if err := os.Remove(part); err != nil && !utils.DoesNotExist(err) && globstate.VerboseLogs {
+		fmt.Printf("cannot remove %s: %s\n", part, err)
+	}
+}
+
+func (d *Download) Download() error {
 	if validateErr := d.Validate(); validateErr != nil {
 		var v *validfile.ValidateError
 
 		if errors.As(validateErr, &v) && v.Mismatch() {
-			shouldDownload = true
-		} else {
-			return validateErr
-		}
-	}
-
-	if shouldDownload {
-		if dlErr := d.download(); dlErr != nil {
-			return dlErr
+			return d.download()
 		}
 
-		// we shall not fail after the downloading
-		if validateErr := d.Validate(); validateErr != nil {
-			return validateErr
-		}
+		return validateErr
 	}
 
+	// the artifact is valid, but temporary file may be left from the download that has been interrupted
+	d.discardPart()
+
 	return nil
 }
 
@@ -114,6 +270,26 @@ func WithURL(u *url.URL) Option {
 	}
 }
 
+// WithSize returns an option that sets the expected size of the artifact, which is used to report progress.
+func WithSize(size uint64) Option {
+	return func(d *Download) error {
+		d.Size = size
+		return nil
+	}
+}
+
+// WithExistenceCheck returns an option that makes the artifact to be downloaded only if it does not exist, for the
+// artifacts which have no known hash.
+func WithExistenceCheck() Option {
+	return func(d *Download) error {
+		d.Validators = append(d.Validators, func(name string) error {
+			return validfile.ValidateExistsFile(name)
+		})
+
+		return nil
+	}
+}
+
 func WithSHA1(hash string) Option {
 	return func(d *Download) error {
 		h, err := hex.DecodeString(hash)
@@ -121,8 +297,8 @@ func WithSHA1(hash string) Option {
 			return fmt.Errorf("decode %q as hex: %w", hash, err)
 		}
 
-		d.Validators = append(d.Validators, func() error {
-			if validateErr := validfile.ValidateFile(d.Destination, sha1.New(), h); validateErr != nil {
+		d.Validators = append(d.Validators, func(name string) error {
+			if validateErr := validfile.ValidateFile(name, sha1.New(), h); validateErr != nil {
 				return fmt.Errorf("validate with sha1: %w", validateErr)
 			}
 			return nil
@@ -132,9 +308,27 @@ func WithSHA1(hash string) Option {
 	}
 }
 
+func WithSHA256(hash string) Option {
+	return func(d *Download) error {
+		h, err := hex.DecodeString(hash)
+		if err != nil {
+			return fmt.Errorf("decode %q as hex: %w", hash, err)
+		}
+
+		d.Validators = append(d.Validators, func(name string) error {
+			if validateErr := validfile.ValidateFile(name, sha256.New(), h); validateErr != nil {
+				return fmt.Errorf("validate with sha256: %w", validateErr)
+			}
+			return nil
+		})
+
+		return nil
+	}
+}
+
 func WithRemoteSHA1() Option {
 	return func(d *Download) error {
-		d.Validators = append(d.Validators, func() error {
+		d.Validators = append(d.Validators, func(name string) error {
 			u := *d.DownloadURL
 			u.Path += ".sha1"
 
@@ -143,7 +337,7 @@ func WithRemoteSHA1() Option {
 				return fmt.Errorf("retrieve remote sha1 from %s: %w", u.String(), retrievalErr)
 			}
 
-			if validateErr := validfile.ValidateFile(d.Destination, sha1.New(), remoteHash); validateErr != nil {
+			if validateErr := validfile.ValidateFile(name, sha1.New(), remoteHash); validateErr != nil {
 				return fmt.Errorf("validate with remote sha1: %w", validateErr)
 			}
 
@@ -161,8 +355,8 @@ func WithMD5(hash string) Option {
 			return fmt.Errorf("decode %q as hex: %w", hash, err)
 		}
 
-		d.Validators = append(d.Validators, func() error {
-			if validateErr := validfile.ValidateFile(d.Destination, md5.New(), h); validateErr != nil {
+		d.Validators = append(d.Validators, func(name string) error {
+			if validateErr := validfile.ValidateFile(name, md5.New(), h); validateErr != nil {
 				return fmt.Errorf("validate with md5: %w", validateErr)
 			}
 			return nil
@@ -174,7 +368,7 @@ func WithMD5(hash string) Option {
 
 func WithRemoteMD5() Option {
 	return func(d *Download) error {
-		d.Validators = append(d.Validators, func() error {
+		d.Validators = append(d.Validators, func(name string) error {
 			retrievalUrl := *d.DownloadURL
 			retrievalUrl.Path += ".md5"
 
@@ -183,7 +377,7 @@ func WithRemoteMD5() Option {
 				return fmt.Errorf("retrieve remote md5 from %s: %w", retrievalUrl.String(), err)
 			}
 
-			if validateErr := validfile.ValidateFile(d.Destination, md5.New(), remoteHash); validateErr != nil {
+			if validateErr := validfile.ValidateFile(name, md5.New(), remoteHash); validateErr != nil {
 				return fmt.Errorf("verify with remote md5: %w", validateErr)
 			}
 
@@ -0,0 +1,146 @@
+package download
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brawaru/marct/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadAtomic(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("corrupted"))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "lib.jar")
+	part := network.PartName(dest)
+
+	if !assert.NoError(t, os.WriteFile(part, []byte("left from the last run"), 0644)) {
+		return
+	}
+
+	err := FromURL(srv.URL, dest, WithSHA1(sha1Hex("expected")))
+
+	assert.Error(t, err)
+	assert.NoFileExists(t, dest, "invalid file must not be moved to the destination")
+	assert.NoFileExists(t, part, "temporary file must be removed")
+}
+
+func TestDownloadReplacesInvalid(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("expected"))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "lib.jar")
+
+	if !assert.NoError(t, os.WriteFile(dest, []byte("truncat"), 0644)) {
+		return
+	}
+
+	if assert.NoError(t, FromURL(srv.URL, dest, WithSHA1(sha1Hex("expected")))) {
+		b, err := os.ReadFile(dest)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "expected", string(b))
+		}
+
+		assert.NoFileExists(t, network.PartName(dest))
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), resumableThreshold/16+1)
+	written := len(content) / 2
+
+	var served int64
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(&countingWriter{ResponseWriter: w, n: &served}, r, "client.jar", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "client.jar")
+
+	d, err := NewURL(srv.URL+"/client.jar", dest, WithSHA1(sha1Hex(string(content))), WithSize(uint64(len(content))))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// as if the previous run has been interrupted half way
+	assert.NoError(t, os.WriteFile(network.PartName(dest), content[:written], 0644))
+	d.writeMarker(`"v1"`)
+
+	var reported int64
+	d.progress = func(n int64) {
+		reported += n
+	}
+
+	if !assert.NoError(t, d.Download()) {
+		return
+	}
+
+	assert.Equal(t, int64(len(content)-written), served, "only the rest of the file must be requested")
+	assert.Equal(t, int64(len(content)), reported)
+	assert.NoFileExists(t, network.PartName(dest))
+	assert.NoFileExists(t, d.markerName())
+
+	b, err := os.ReadFile(dest)
+	if assert.NoError(t, err) {
+		assert.True(t, bytes.Equal(content, b))
+	}
+}
+
+func TestDownloadResumeChanged(t *testing.T) {
+	content := bytes.Repeat([]byte("fedcba9876543210"), resumableThreshold/16+1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "client.jar", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "client.jar")
+
+	d, err := NewURL(srv.URL+"/client.jar", dest, WithSHA1(sha1Hex(string(content))), WithSize(uint64(len(content))))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the previous run has been downloading the content that is no longer served
+	assert.NoError(t, os.WriteFile(network.PartName(dest), bytes.Repeat([]byte("x"), len(content)+10), 0644))
+	d.writeMarker(`"v1"`)
+
+	var reported int64
+	d.progress = func(n int64) {
+		reported += n
+	}
+
+	if assert.NoError(t, d.Download()) {
+		assert.Equal(t, int64(len(content)), reported, "progress of the discarded bytes must be taken back")
+
+		b, err := os.ReadFile(dest)
+		if assert.NoError(t, err) {
+			assert.True(t, bytes.Equal(content, b))
+		}
+	}
+}
+
+// countingWriter counts the bytes of the response body.
+type countingWriter struct {
+	http.ResponseWriter
+	n *int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(b)
+	*c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,208 @@
+package download
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/brawaru/marct/utils/terrgroup"
+)
+
+// DefaultConcurrency is the number of downloads a manager runs at once, unless told otherwise.
+const DefaultConcurrency = 8
+
+// FileProgress is the progress of a single download in the batch.
+type FileProgress struct {
+	Destination string // Where the artifact is downloaded.
+	BytesDone   uint64 // Number of bytes downloaded so far.
+	BytesTotal  uint64 // Expected size of the artifact, 0 if unknown.
+	Done        bool   // Whether the artifact is downloaded and valid.
+}
+
+// Progress is the progress of the whole batch, reported each time any of its downloads progresses.
+type Progress struct {
+	File       *FileProgress // Download that has progressed, nil for the report made when the batch starts.
+	FilesDone  int           // Number of artifacts that are downloaded and valid.
+	FilesTotal int           // Number of artifacts in the batch.
+	BytesDone  uint64        // Number of bytes downloaded so far, including artifacts that were already valid.
+	BytesTotal uint64        // Expected size of the batch, including artifacts which size has become known.
+	ETA        time.Duration // Estimated time left, 0 if it cannot be estimated yet.
+}
+
+// Manager runs batches of downloads with a limited concurrency and reports their progress to the subscribers.
+type Manager struct {
+	concurrency int
+
+	mu          sync.Mutex
+	subscribers map[int]func(Progress)
+	nextID      int
+}
+
+// NewManager creates a manager running at most concurrency downloads at once.
+func NewManager(concurrency int) *Manager {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &Manager{
+		concurrency: concurrency,
+		subscribers: map[int]func(Progress){},
+	}
+}
+
+// Subscribe registers the function to be called with the progress of the batches, never concurrently. The returned
+// function removes the subscription.
+func (m *Manager) Subscribe(fn func(Progress)) (unsubscribe func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.nextID
+	m.nextID++
+	m.subscribers[id] = fn
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		delete(m.subscribers, id)
+	}
+}
+
+// batch tracks the progress of the downloads run at once.
+type batch struct {
+	mu          sync.Mutex
+	subscribers []func(Progress)
+	progress    Progress
+	started     time.Time
+	streamed    uint64 // Bytes actually received, used to estimate the download speed.
+}
+
+func (b *batch) report(file *FileProgress) {
+	b.progress.File = nil
+	if file != nil {
+		// subscribers may keep the report, while the file is still progressing
+		c := *file
+		b.progress.File = &c
+	}
+	b.progress.ETA = 0
+
+	if elapsed := time.Since(b.started); b.streamed != 0 && elapsed > 0 && b.progress.BytesTotal > b.progress.BytesDone {
+		rate := float64(b.streamed) / elapsed.Seconds()
+		b.progress.ETA = time.Duration(float64(b.progress.BytesTotal-b.progress.BytesDone) / rate * float64(time.Second))
+	}
+
+	for _, fn := range b.subscribers {
+		fn(b.progress)
+	}
+}
+
+// advance accounts bytes written to the file, or taken back if written is negative, as the file is rewound.
+func (b *batch) advance(f *FileProgress, written int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	done := uint64(0)
+	if written > 0 {
+		b.streamed += uint64(written)
+		done = f.BytesDone + uint64(written)
+	} else if uint64(-written) < f.BytesDone {
+		done = f.BytesDone - uint64(-written)
+	}
+
+	if f.BytesTotal == 0 {
+		// size is not known ahead, so the total follows the file as it is being downloaded
+		b.progress.BytesTotal = b.progress.BytesTotal - f.BytesDone + done
+		b.progress.BytesDone = b.progress.BytesDone - f.BytesDone + done
+	} else {
+		b.progress.BytesDone = b.progress.BytesDone - capBytes(f.BytesDone, f.BytesTotal) + capBytes(done, f.BytesTotal)
+	}
+
+	f.BytesDone = done
+
+	b.report(f)
+}
+
+// capBytes returns the number of bytes done that are accounted towards the expected size.
+func capBytes(done, total uint64) uint64 {
+	if done > total {
+		return total
+	}
+
+	return done
+}
+
+// complete marks the file as done, accounting the rest of its size if it was already valid and has not been
+// downloaded.
+func (b *batch) complete(f *FileProgress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if f.BytesDone < f.BytesTotal {
+		b.progress.BytesDone += f.BytesTotal - f.BytesDone
+		f.BytesDone = f.BytesTotal
+	}
+
+	f.Done = true
+	b.progress.FilesDone++
+
+	b.report(f)
+}
+
+// Run downloads the artifacts, validating existing ones first, and waits for all of them to complete. Artifacts with
+// the same destination are only downloaded once. The first error encountered is returned.
+func (m *Manager) Run(downloads []*Download) error {
+	m.mu.Lock()
+	b := &batch{started: time.Now()}
+	for _, fn := range m.subscribers {
+		b.subscribers = append(b.subscribers, fn)
+	}
+	m.mu.Unlock()
+
+	seen := map[string]bool{}
+
+	var unique []*Download
+
+	for _, d := range downloads {
+		if seen[d.Destination] {
+			continue
+		}
+
+		seen[d.Destination] = true
+		unique = append(unique, d)
+
+		b.progress.FilesTotal++
+		b.progress.BytesTotal += d.Size
+	}
+
+	b.mu.Lock()
+	b.report(nil)
+	b.mu.Unlock()
+
+	g, _ := terrgroup.New(m.concurrency)
+
+	for _, dl := range unique {
+		d := dl
+		f := &FileProgress{Destination: d.Destination, BytesTotal: d.Size}
+
+		d.progress = func(written int64) {
+			b.advance(f, written)
+		}
+
+		g.Go(func() error {
+			if err := d.Download(); err != nil {
+				return fmt.Errorf("download %s: %w", d.DownloadURL, err)
+			}
+
+			b.complete(f)
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// Run downloads the artifacts using a manager with the default concurrency and no subscribers.
+func Run(downloads []*Download) error {
+	return NewManager(DefaultConcurrency).Run(downloads)
+}
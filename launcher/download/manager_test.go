@@ -0,0 +1,133 @@
+package download
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sha1Hex(s string) string {
+	h := sha1.Sum([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func TestManagerRun(t *testing.T) {
+	files := map[string]string{
+		"/a": strings.Repeat("a", 4096),
+		"/b": strings.Repeat("b", 100),
+		"/c": "already here",
+	}
+
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		body, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	if !assert.NoError(t, os.WriteFile(filepath.Join(dir, "c"), []byte(files["/c"]), 0644)) {
+		return
+	}
+
+	var downloads []*Download
+
+	for _, name := range []string{"a", "b", "c", "a"} {
+		body := files["/"+name]
+
+		d, err := NewURL(srv.URL+"/"+name, filepath.Join(dir, name), WithSHA1(sha1Hex(body)), WithSize(uint64(len(body))))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		downloads = append(downloads, d)
+	}
+
+	m := NewManager(2)
+
+	var reports []Progress
+
+	unsubscribe := m.Subscribe(func(p Progress) {
+		reports = append(reports, p)
+	})
+	defer unsubscribe()
+
+	if !assert.NoError(t, m.Run(downloads)) {
+		return
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "valid and duplicate files must not be downloaded")
+
+	if !assert.NotEmpty(t, reports) {
+		return
+	}
+
+	first := reports[0]
+	assert.Nil(t, first.File)
+	assert.Equal(t, 3, first.FilesTotal)
+	assert.Equal(t, uint64(4096+100+12), first.BytesTotal)
+	assert.Equal(t, 0, first.FilesDone)
+
+	last := reports[len(reports)-1]
+	assert.Equal(t, last.FilesTotal, last.FilesDone)
+	assert.Equal(t, last.BytesTotal, last.BytesDone)
+	assert.Equal(t, uint64(4096+100+12), last.BytesDone)
+
+	for i := 1; i < len(reports); i++ {
+		assert.GreaterOrEqual(t, reports[i].BytesDone, reports[i-1].BytesDone, "progress must not go backwards")
+		assert.NotNil(t, reports[i].File)
+	}
+}
+
+func TestManagerRunError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	d, err := NewURL(srv.URL+"/missing", filepath.Join(t.TempDir(), "missing"), WithSHA1(sha1Hex("x")))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Error(t, Run([]*Download{d}))
+}
+
+func TestBatchAdvanceRewound(t *testing.T) {
+	b := &batch{started: time.Now()}
+
+	known := &FileProgress{BytesTotal: 100}
+	unknown := &FileProgress{}
+	b.progress.BytesTotal = known.BytesTotal
+
+	b.advance(known, 120)
+	b.advance(unknown, 50)
+	assert.Equal(t, uint64(150), b.progress.BytesDone)
+	assert.Equal(t, uint64(150), b.progress.BytesTotal)
+
+	// file is written from the beginning again, so the bytes written so far are taken back
+	b.advance(known, -120)
+	b.advance(unknown, -50)
+	assert.Equal(t, uint64(0), b.progress.BytesDone)
+	assert.Equal(t, uint64(100), b.progress.BytesTotal)
+	assert.Equal(t, uint64(0), known.BytesDone)
+
+	b.advance(known, 100)
+	assert.Equal(t, uint64(100), b.progress.BytesDone)
+}
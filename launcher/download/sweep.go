@@ -0,0 +1,104 @@
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brawaru/marct/globstate"
+	"github.com/brawaru/marct/network"
+	"github.com/brawaru/marct/utils"
+)
+
+const (
+	// partStaleAfter is how long the temporary file must not be written to before it is considered abandoned. It
+	// keeps the files written by other running processes.
+	partStaleAfter = time.Hour
+	// resumableStaleAfter is how long the temporary file that can be resumed is kept for the next run.
+	resumableStaleAfter = 7 * 24 * time.Hour
+)
+
+// resumablePart checks whether the temporary file has a valid resume marker next to it.
+func resumablePart(part string) bool {
+	b, err := os.ReadFile(part + resumeMarkerSuffix)
+	if err != nil {
+		return false
+	}
+
+	var m resumeMarker
+	return json.Unmarshal(b, &m) == nil && m.URL != "" && m.Validator != ""
+}
+
+// sweepPart removes the temporary file and its resume marker if they have been abandoned.
+func sweepPart(part string, now time.Time) {
+	stat, err := os.Stat(part)
+	if err != nil {
+		if utils.DoesNotExist(err) {
+			// marker of the temporary file that is gone
+			if stat, err := os.Stat(part + resumeMarkerSuffix); err == nil && now.Sub(stat.ModTime()) > partStaleAfter {
+				removePart(part + resumeMarkerSuffix)
+			}
+		}
+
+		return
+	}
+
+	staleAfter := partStaleAfter
+	if resumablePart(part) {
+		staleAfter = resumableStaleAfter
+	}
+
+	if now.Sub(stat.ModTime()) <= staleAfter {
+		return
+	}
+
+	if globstate.VerboseLogs {
+		fmt.Printf("removing abandoned %s\n", part)
+	}
+
+	removePart(part)
+	removePart(part + resumeMarkerSuffix)
+}
+
+// SweepParts removes temporary files left in the directories by the downloads that have been interrupted and never
+// repeated, for example, because the artifact is no longer needed. Temporary files that can be resumed are kept for a
+// while, and the ones that are still being written to by other processes are left intact. Directories that do not exist
+// are skipped.
+func SweepParts(dirs ...string) error {
+	now := time.Now()
+
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if utils.DoesNotExist(err) {
+					return nil
+				}
+
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			switch {
+			case strings.HasSuffix(name, network.PartSuffix):
+				sweepPart(name, now)
+			case strings.HasSuffix(name, network.PartSuffix+resumeMarkerSuffix):
+				sweepPart(strings.TrimSuffix(name, resumeMarkerSuffix), now)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return fmt.Errorf("sweep %q: %w", dir, err)
+		}
+	}
+
+	return nil
+}
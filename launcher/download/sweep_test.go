@@ -0,0 +1,48 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweepParts(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name string, content string, age time.Duration) string {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755)) || !assert.NoError(t, os.WriteFile(p, []byte(content), 0644)) {
+			t.FailNow()
+		}
+
+		mtime := time.Now().Add(-age)
+		assert.NoError(t, os.Chtimes(p, mtime, mtime))
+
+		return p
+	}
+
+	marker := `{"url":"https://example.com/a","validator":"\"v1\""}`
+
+	abandoned := write("a/old.jar.part", "x", 2*time.Hour)
+	active := write("a/active.jar.part", "x", time.Minute)
+	resumable := write("b/large.jar.part", "x", 2*time.Hour)
+	resumableMarker := write("b/large.jar.part.resume", marker, 2*time.Hour)
+	expired := write("b/expired.jar.part", "x", 8*24*time.Hour)
+	expiredMarker := write("b/expired.jar.part.resume", marker, 8*24*time.Hour)
+	orphanMarker := write("c/gone.jar.part.resume", marker, 2*time.Hour)
+	artifact := write("c/valid.jar", "x", 30*24*time.Hour)
+
+	assert.NoError(t, SweepParts(dir, filepath.Join(dir, "missing")))
+
+	assert.NoFileExists(t, abandoned)
+	assert.FileExists(t, active, "file that is being written to must be kept")
+	assert.FileExists(t, resumable, "file that can be resumed must be kept")
+	assert.FileExists(t, resumableMarker)
+	assert.NoFileExists(t, expired)
+	assert.NoFileExists(t, expiredMarker)
+	assert.NoFileExists(t, orphanMarker)
+	assert.FileExists(t, artifact)
+}
@@ -0,0 +1,142 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/brawaru/marct/utils"
+	"github.com/rogpeppe/go-internal/lockedfile"
+)
+
+// gameDirOwner is the content of the game directory owner file.
+type gameDirOwner struct {
+	SessionID string `json:"sessionId,omitempty"` // Identifier of the session, empty until the game is started.
+	ProfileID string `json:"profileId,omitempty"` // Identifier of the profile launched.
+	PID       int    `json:"pid"`                 // Process ID of the owner, the launcher until the game is started.
+	StartTime int64  `json:"startTime,omitempty"` // Start time of the owner process, zero if unknown.
+}
+
+// newGameDirOwner creates the owner record for the process with the PID.
+func newGameDirOwner(sessionID string, profileID string, pid int) *gameDirOwner {
+	// without start time, the record is only checked by PID
+	startTime, _ := processStartTime(pid)
+
+	return &gameDirOwner{
+		SessionID: sessionID,
+		ProfileID: profileID,
+		PID:       pid,
+		StartTime: startTime,
+	}
+}
+
+// alive checks whether the owner process is still running, and not replaced by another process given the same PID.
+func (o *gameDirOwner) alive() bool {
+	return processRunning(o.PID, o.StartTime)
+}
+
+// ownedBy checks whether the owner is the process with the PID that is known to be alive.
+func (o *gameDirOwner) ownedBy(pid int) bool {
+	return o.PID == pid && sameProcess(pid, o.StartTime)
+}
+
+// editGameDirOwner locks the owner file of the game directory and lets edit change its content. If edit returns nil,
+// the owner file is emptied. The lock is held only while edit runs.
+func editGameDirOwner(dir string, edit func(current *gameDirOwner) (*gameDirOwner, error)) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot create game directory: %w", err)
+	}
+
+	name := filepath.Join(dir, gameDirOwnerFile)
+
+	f, err := lockedfile.Edit(name)
+	if err != nil {
+		return fmt.Errorf("lock %s: %w", name, err)
+	}
+
+	defer utils.DClose(f)
+
+	var current *gameDirOwner
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", name, err)
+	}
+
+	// file that cannot be decoded is likely a result of crash, treating it as free
+	if len(b) != 0 && json.Unmarshal(b, &current) != nil {
+		current = nil
+	}
+
+	next, err := edit(current)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("truncate %s: %w", name, err)
+	}
+
+	if next == nil {
+		return nil
+	}
+
+	b, err = json.Marshal(next)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+
+	if _, err := f.WriteAt(b, 0); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// lockGameDirectory marks the game directory as used by the launcher process. If the directory is used by another
+// session that is still alive, GameDirectoryBusyError is returned, unless force is true.
+func lockGameDirectory(dir string, profileID string, force bool) error {
+	return editGameDirOwner(dir, func(current *gameDirOwner) (*gameDirOwner, error) {
+		if current != nil && !force && !current.ownedBy(os.Getpid()) && current.alive() {
+			return nil, &GameDirectoryBusyError{
+				Directory: dir,
+				SessionID: current.SessionID,
+				PID:       current.PID,
+			}
+		}
+
+		return newGameDirOwner("", profileID, os.Getpid()), nil
+	})
+}
+
+// transferGameDirectory makes the started game process the owner of the game directory, so the directory stays locked
+// for as long as the game is running, even if the launcher exits. If the directory is no longer owned by the launcher
+// process, for example, because another session has forcefully taken it over, GameDirectoryBusyError is returned.
+func transferGameDirectory(dir string, sessionID string, pid int) error {
+	return editGameDirOwner(dir, func(current *gameDirOwner) (*gameDirOwner, error) {
+		if current == nil || !current.ownedBy(os.Getpid()) {
+			busyErr := &GameDirectoryBusyError{Directory: dir}
+			if current != nil {
+				busyErr.SessionID = current.SessionID
+				busyErr.PID = current.PID
+			}
+
+			return nil, busyErr
+		}
+
+		return newGameDirOwner(sessionID, current.ProfileID, pid), nil
+	})
+}
+
+// unlockGameDirectory marks the game directory as free, if it's still owned by the process with the PID.
+func unlockGameDirectory(dir string, pid int) error {
+	return editGameDirOwner(dir, func(current *gameDirOwner) (*gameDirOwner, error) {
+		if current != nil && current.PID != pid {
+			return current, nil // forcefully taken over by another session
+		}
+
+		return nil, nil
+	})
+}
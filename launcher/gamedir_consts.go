@@ -0,0 +1,7 @@
+package launcher
+
+const (
+	// Name of the file in the game directory recording the session that uses the directory. The file is only accessed
+	// while holding a lock on it.
+	gameDirOwnerFile = ".marct-session.json"
+)
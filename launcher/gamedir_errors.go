@@ -0,0 +1,23 @@
+package launcher
+
+import "fmt"
+
+// GameDirectoryBusyError is returned when the game directory is already used by another running session.
+type GameDirectoryBusyError struct {
+	Directory string // Path to the game directory.
+	SessionID string // Identifier of the session using the directory, empty if the session is still starting.
+	PID       int    // Process ID of the owner of the directory.
+}
+
+func (e *GameDirectoryBusyError) Error() string {
+	if e.SessionID == "" {
+		return fmt.Sprintf("game directory %s is used by process %d", e.Directory, e.PID)
+	}
+
+	return fmt.Sprintf("game directory %s is used by session %s (process %d)", e.Directory, e.SessionID, e.PID)
+}
+
+func (e *GameDirectoryBusyError) Is(target error) bool {
+	t, ok := target.(*GameDirectoryBusyError)
+	return ok && (t.Directory == "" || e.Directory == t.Directory)
+}
@@ -0,0 +1,105 @@
+package launcher
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameDirectoryLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on POSIX true command")
+	}
+
+	dir := t.TempDir()
+
+	// parent process of the test is alive for as long as the test runs
+	owner := os.Getppid()
+
+	if !assert.NoError(t, lockGameDirectory(dir, "first", false)) {
+		return
+	}
+
+	if !assert.NoError(t, transferGameDirectory(dir, "session", owner)) {
+		return
+	}
+
+	err := lockGameDirectory(dir, "second", false)
+	if assert.ErrorIs(t, err, &GameDirectoryBusyError{Directory: dir}) {
+		var busyErr *GameDirectoryBusyError
+		if assert.ErrorAs(t, err, &busyErr) {
+			assert.Equal(t, "session", busyErr.SessionID)
+			assert.Equal(t, owner, busyErr.PID)
+		}
+	}
+
+	assert.NoError(t, lockGameDirectory(dir, "second", true), "force must override the lock")
+
+	// lock taken over by force must not be released by the previous owner
+	assert.NoError(t, unlockGameDirectory(dir, owner))
+	assert.NoError(t, editGameDirOwner(dir, func(current *gameDirOwner) (*gameDirOwner, error) {
+		if assert.NotNil(t, current) {
+			assert.Equal(t, os.Getpid(), current.PID)
+		}
+
+		return current, nil
+	}))
+
+	assert.NoError(t, transferGameDirectory(dir, "forced", owner))
+	assert.NoError(t, unlockGameDirectory(dir, owner))
+	assert.NoError(t, lockGameDirectory(dir, "third", false), "released lock must be free")
+
+	dead := exec.Command("true")
+	if !assert.NoError(t, dead.Run()) {
+		return
+	}
+
+	assert.NoError(t, transferGameDirectory(dir, "dead", dead.ProcessState.Pid()))
+	assert.NoError(t, lockGameDirectory(dir, "fourth", false), "lock of the dead session must be free")
+}
+
+func TestGameDirectoryTakenOver(t *testing.T) {
+	dir := t.TempDir()
+
+	if !assert.NoError(t, lockGameDirectory(dir, "first", false)) {
+		return
+	}
+
+	// another launch forcefully takes the directory over while this one is preparing
+	other := os.Getppid()
+	assert.NoError(t, editGameDirOwner(dir, func(*gameDirOwner) (*gameDirOwner, error) {
+		return newGameDirOwner("", "second", other), nil
+	}))
+
+	err := transferGameDirectory(dir, "session", os.Getpid())
+	assert.ErrorIs(t, err, &GameDirectoryBusyError{Directory: dir})
+
+	assert.NoError(t, editGameDirOwner(dir, func(current *gameDirOwner) (*gameDirOwner, error) {
+		if assert.NotNil(t, current) {
+			assert.Equal(t, other, current.PID, "owner must be left intact")
+		}
+
+		return current, nil
+	}))
+}
+
+func TestGameDirectoryReusedPID(t *testing.T) {
+	owner := os.Getppid()
+
+	startTime, err := processStartTime(owner)
+	if err != nil {
+		t.Skip("process start time is not available on this system")
+	}
+
+	dir := t.TempDir()
+
+	// record left by the crashed session, whose PID has been given to another process since
+	assert.NoError(t, editGameDirOwner(dir, func(*gameDirOwner) (*gameDirOwner, error) {
+		return &gameDirOwner{SessionID: "crashed", PID: owner, StartTime: startTime - 1}, nil
+	}))
+
+	assert.NoError(t, lockGameDirectory(dir, "next", false), "lock of the process with reused PID must be free")
+}
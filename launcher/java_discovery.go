@@ -0,0 +1,141 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// JavaSource describes where the Java installation has been found.
+type JavaSource string
+
+const (
+	JavaSourceJavaHome  JavaSource = "JAVA_HOME" // Installation pointed by JAVA_HOME environment variable.
+	JavaSourcePath      JavaSource = "PATH"      // Java executable found in PATH.
+	JavaSourceSystem    JavaSource = "system"    // Installation managed by the system package manager.
+	JavaSourceSDKMAN    JavaSource = "sdkman"    // Installation managed by SDKMAN!.
+	JavaSourceJDKs      JavaSource = "jdks"      // Installation downloaded by IntelliJ IDEA.
+	JavaSourceMinecraft JavaSource = "minecraft" // Mojang runtime installed by the official launcher.
+)
+
+// DiscoveredJava is the Java installation found on the system.
+type DiscoveredJava struct {
+	JavaInstallation
+	Source JavaSource // Where the installation has been found.
+}
+
+// javaCandidate is a path that may contain the Java executable.
+type javaCandidate struct {
+	source JavaSource
+	path   string
+}
+
+// javaBinary returns the path to the Java executable in the directory.
+func javaBinary(dir string) string {
+	name := filepath.Join(dir, "java")
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	return name
+}
+
+// javaExecutable returns the path to the Java executable in the Java home directory.
+func javaExecutable(home string) string {
+	return javaBinary(filepath.Join(home, "bin"))
+}
+
+// officialMinecraftPath returns the path to the directory of the official launcher.
+func officialMinecraftPath() string {
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("APPDATA"), ".minecraft")
+	case "darwin":
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, "Library", "Application Support", "minecraft")
+	default:
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, ".minecraft")
+	}
+}
+
+// javaCandidates lists paths where the Java executables may be found, in order of preference.
+func javaCandidates() []javaCandidate {
+	var candidates []javaCandidate
+
+	add := func(source JavaSource, pattern string) {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			candidates = append(candidates, javaCandidate{source: source, path: m})
+		}
+	}
+
+	if h := os.Getenv("JAVA_HOME"); h != "" {
+		candidates = append(candidates, javaCandidate{source: JavaSourceJavaHome, path: javaExecutable(h)})
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" {
+			candidates = append(candidates, javaCandidate{source: JavaSourcePath, path: javaBinary(dir)})
+		}
+	}
+
+	if runtime.GOOS == "linux" {
+		add(JavaSourceSystem, javaExecutable("/usr/lib/jvm/*"))
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		add(JavaSourceSDKMAN, javaExecutable(filepath.Join(home, ".sdkman", "candidates", "java", "*")))
+		add(JavaSourceJDKs, javaExecutable(filepath.Join(home, ".jdks", "*")))
+	}
+
+	// runtime/{component}/{selector}/{component}/bin/java, same layout as in JREPath
+	add(JavaSourceMinecraft, javaExecutable(filepath.Join(officialMinecraftPath(), "runtime", "*", GetJRESelector(), "*")))
+
+	return candidates
+}
+
+// DiscoverJava finds Java installations on the system and probes them. Candidates that are links to the same
+// executable are listed once, under the first source they have been found in. Installations that cannot be probed are
+// skipped and their errors returned alongside.
+func (w *Instance) DiscoverJava() ([]DiscoveredJava, []error) {
+	var found []DiscoveredJava
+	var errs []error
+
+	seen := map[string]bool{}
+
+	for _, c := range javaCandidates() {
+		stat, err := os.Stat(c.path)
+		if err != nil || stat.IsDir() {
+			continue
+		}
+
+		resolved, err := filepath.EvalSymlinks(c.path)
+		if err != nil {
+			continue
+		}
+
+		// PATH entries like /usr/bin/java are usually links to the system installations, which are found later
+		key := resolved
+		if runtime.GOOS == "windows" {
+			key = strings.ToLower(key)
+		}
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		java, err := w.ProbeJava(c.path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		found = append(found, DiscoveredJava{JavaInstallation: *java, Source: c.source})
+	}
+
+	return found, errs
+}
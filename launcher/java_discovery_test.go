@@ -0,0 +1,56 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverJava(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake java is a shell script")
+	}
+
+	home := t.TempDir()
+	bin := t.TempDir()
+
+	sdkmanJava := javaExecutable(filepath.Join(home, ".sdkman", "candidates", "java", "17.0.2-tem"))
+	jdksJava := javaExecutable(filepath.Join(home, ".jdks", "temurin-17.0.2"))
+
+	for _, name := range []string{sdkmanJava, jdksJava} {
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0755)) {
+			return
+		}
+
+		if !assert.NoError(t, os.WriteFile(name, []byte(fakeJavaScript), 0755)) {
+			return
+		}
+	}
+
+	// java in PATH is a link to SDKMAN! installation and must be listed once
+	if !assert.NoError(t, os.Symlink(sdkmanJava, javaBinary(bin))) {
+		return
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("JAVA_HOME", "")
+	t.Setenv("PATH", bin)
+
+	w := &Instance{Path: t.TempDir()}
+
+	found, errs := w.DiscoverJava()
+	assert.Empty(t, errs)
+
+	sources := map[string]JavaSource{}
+	for _, j := range found {
+		assert.Equal(t, 17, j.MajorVersion)
+		sources[j.Path] = j.Source
+	}
+
+	assert.Equal(t, JavaSourcePath, sources[javaBinary(bin)])
+	assert.Equal(t, JavaSourceJDKs, sources[jdksJava])
+	assert.NotContains(t, sources, sdkmanJava)
+}
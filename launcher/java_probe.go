@@ -0,0 +1,238 @@
+package launcher
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brawaru/marct/globstate"
+	"github.com/brawaru/marct/utils"
+	"github.com/rogpeppe/go-internal/lockedfile"
+)
+
+// JavaInstallation describes Java installation determined by probing its executable.
+type JavaInstallation struct {
+	Path         string `json:"path"`         // Absolute path to the Java executable.
+	Vendor       string `json:"vendor"`       // Vendor of the Java, e.g. "Eclipse Adoptium".
+	Version      string `json:"version"`      // Full version of the Java, e.g. "17.0.2" or "1.8.0_302".
+	MajorVersion int    `json:"majorVersion"` // Major version of the Java, e.g. 17 or 8.
+	Arch         string `json:"arch"`         // Architecture the Java is built for, e.g. "amd64".
+}
+
+// javaProbeCacheEntry is an entry of the probing cache, valid as long as the executable is not modified.
+type javaProbeCacheEntry struct {
+	ModTime time.Time        `json:"modTime"`
+	Size    int64            `json:"size"`
+	Java    JavaInstallation `json:"java"`
+}
+
+// parseJavaMajorVersion parses major version from the Java version string, handling both old "1.x" scheme and the
+// new one introduced in Java 9.
+func parseJavaMajorVersion(version string) (int, error) {
+	s := version
+	if strings.HasPrefix(s, "1.") {
+		s = s[2:]
+	}
+
+	if end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		s = s[:end]
+	}
+
+	major, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid java version %q", version)
+	}
+
+	return major, nil
+}
+
+// parseJavaProperties parses the output of "java -XshowSettings:properties -version".
+func parseJavaProperties(output []byte) (*JavaInstallation, error) {
+	props := map[string]string{}
+
+	s := bufio.NewScanner(bytes.NewReader(output))
+	for s.Scan() {
+		line := s.Text()
+
+		// properties are indented with four spaces, continuation of the multi-value properties with eight
+		if !strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "        ") {
+			continue
+		}
+
+		k, v, ok := strings.Cut(strings.TrimSpace(line), " = ")
+		if ok {
+			props[k] = v
+		}
+	}
+
+	version := props["java.version"]
+	if version == "" {
+		return nil, errors.New("java.version property is missing")
+	}
+
+	spec := props["java.specification.version"]
+	if spec == "" {
+		spec = version
+	}
+
+	major, err := parseJavaMajorVersion(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JavaInstallation{
+		Vendor:       props["java.vendor"],
+		Version:      version,
+		MajorVersion: major,
+		Arch:         props["os.arch"],
+	}, nil
+}
+
+// readJavaProbesCache reads the probing cache. Cache that cannot be read is treated as empty.
+func (w *Instance) readJavaProbesCache() map[string]javaProbeCacheEntry {
+	b, err := lockedfile.Read(filepath.Join(w.Path, javaProbesCacheFile))
+	if err != nil {
+		return nil
+	}
+
+	var cache map[string]javaProbeCacheEntry
+	if json.Unmarshal(b, &cache) != nil {
+		return nil
+	}
+
+	return cache
+}
+
+// writeJavaProbe stores the probing result in the cache.
+func (w *Instance) writeJavaProbe(path string, entry javaProbeCacheEntry) error {
+	name := filepath.Join(w.Path, javaProbesCacheFile)
+
+	f, err := lockedfile.Edit(name)
+	if err != nil {
+		return fmt.Errorf("lock %s: %w", name, err)
+	}
+
+	defer utils.DClose(f)
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", name, err)
+	}
+
+	var cache map[string]javaProbeCacheEntry
+	if len(b) != 0 && json.Unmarshal(b, &cache) != nil {
+		cache = nil
+	}
+
+	if cache == nil {
+		cache = map[string]javaProbeCacheEntry{}
+	}
+
+	cache[path] = entry
+
+	b, err = json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("truncate %s: %w", name, err)
+	}
+
+	if _, err := f.WriteAt(b, 0); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ProbeJava runs the Java executable to find out its vendor, version and architecture. Result is cached until the
+// executable is modified. Path may be a name of the executable in PATH.
+func (w *Instance) ProbeJava(path string) (*JavaInstallation, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, &JavaProbeError{Path: path, Err: err}
+	}
+
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return nil, &JavaProbeError{Path: path, Err: err}
+	}
+
+	stat, err := os.Stat(resolved)
+	if err != nil {
+		return nil, &JavaProbeError{Path: path, Err: err}
+	}
+
+	if e, ok := w.readJavaProbesCache()[resolved]; ok && e.ModTime.Equal(stat.ModTime()) && e.Size == stat.Size() {
+		java := e.Java
+		return &java, nil
+	}
+
+	// properties and the version are printed to stderr
+	output, err := exec.Command(resolved, "-XshowSettings:properties", "-version").CombinedOutput()
+	if err != nil {
+		return nil, &JavaProbeError{Path: path, Err: err}
+	}
+
+	java, err := parseJavaProperties(output)
+	if err != nil {
+		return nil, &JavaProbeError{Path: path, Err: err}
+	}
+
+	java.Path = resolved
+
+	// cache only saves probing next time, so the instance that cannot be written to is not a reason to fail
+	if err := w.writeJavaProbe(resolved, javaProbeCacheEntry{
+		ModTime: stat.ModTime(),
+		Size:    stat.Size(),
+		Java:    *java,
+	}); err != nil && globstate.VerboseLogs {
+		fmt.Printf("cannot cache probe of %s: %s\n", resolved, err)
+	}
+
+	return java, nil
+}
+
+// RequiredJavaMajorVersion returns major version of Java the version needs.
+func RequiredJavaMajorVersion(version Version) int {
+	if version.JavaVersion == nil {
+		return legacyJavaMajorVersion
+	}
+
+	return version.JavaVersion.MajorVersion
+}
+
+// CheckJava probes the Java executable and checks that it can run the version. If the Java is older than required,
+// JavaVersionMismatchError is returned along with the probed installation.
+func (w *Instance) CheckJava(path string, version Version) (*JavaInstallation, error) {
+	java, err := w.ProbeJava(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if required := RequiredJavaMajorVersion(version); java.MajorVersion < required {
+		return java, &JavaVersionMismatchError{
+			Path:     java.Path,
+			Required: required,
+			Actual:   java.MajorVersion,
+		}
+	}
+
+	return java, nil
+}
+
+// LegacyJavaMismatch checks whether the version requires Java 8, which newer Java may not be able to run, for example,
+// when the version uses LaunchWrapper that relies on the system class loader being URLClassLoader.
+func (j *JavaInstallation) LegacyJavaMismatch(version Version) bool {
+	return RequiredJavaMajorVersion(version) <= legacyJavaMajorVersion && j.MajorVersion > legacyJavaMajorVersion
+}
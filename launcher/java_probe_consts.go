@@ -0,0 +1,10 @@
+package launcher
+
+const (
+	// Name of the file in the instance directory caching results of Java probing. Entries are keyed by the absolute path
+	// to the Java executable and invalidated when modification time or size of the executable changes.
+	javaProbesCacheFile = "marct_java_probes.json"
+
+	// Major version of Java used by versions that do not specify the Java version they need.
+	legacyJavaMajorVersion = 8
+)
@@ -0,0 +1,41 @@
+package launcher
+
+import "fmt"
+
+// JavaProbeError is returned when the Java executable cannot be run or its output cannot be understood.
+type JavaProbeError struct {
+	Path string // Path to the Java executable.
+	Err  error  // Cause of the error.
+}
+
+func (e *JavaProbeError) Error() string {
+	return fmt.Sprintf("cannot probe java %s: %s", e.Path, e.Err)
+}
+
+func (e *JavaProbeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *JavaProbeError) Is(target error) bool {
+	t, ok := target.(*JavaProbeError)
+	return ok && (t.Path == "" || e.Path == t.Path)
+}
+
+// JavaVersionMismatchError is returned when the Java is older than the version requires.
+type JavaVersionMismatchError struct {
+	Path     string // Path to the Java executable.
+	Required int    // Major version of Java required by the version.
+	Actual   int    // Major version of the Java.
+}
+
+func (e *JavaVersionMismatchError) Error() string {
+	return fmt.Sprintf("java %s is version %d, but at least %d is required", e.Path, e.Actual, e.Required)
+}
+
+func (e *JavaVersionMismatchError) Is(target error) bool {
+	t, ok := target.(*JavaVersionMismatchError)
+	return ok &&
+		(t.Path == "" || e.Path == t.Path) &&
+		(t.Required == 0 || e.Required == t.Required) &&
+		(t.Actual == 0 || e.Actual == t.Actual)
+}
@@ -0,0 +1,123 @@
+package launcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const javaPropertiesOutput = `Property settings:
+    file.encoding = UTF-8
+    java.library.path = /usr/java/packages/lib
+        /usr/lib64
+    java.specification.version = 17
+    java.vendor = Eclipse Adoptium
+    java.version = 17.0.2
+    os.arch = amd64
+
+openjdk version "17.0.2" 2022-01-18
+`
+
+// fakeJavaScript prints properties like Java does, using only shell builtins so that it works with any PATH.
+const fakeJavaScript = "#!/bin/sh\nprintf '%s' '" + javaPropertiesOutput + "' >&2\n"
+
+func TestParseJavaMajorVersion(t *testing.T) {
+	for version, expected := range map[string]int{
+		"1.8.0_302": 8,
+		"1.8":       8,
+		"17.0.2":    17,
+		"17":        17,
+		"21-ea":     21,
+	} {
+		actual, err := parseJavaMajorVersion(version)
+		if assert.NoError(t, err, version) {
+			assert.Equal(t, expected, actual, version)
+		}
+	}
+
+	_, err := parseJavaMajorVersion("unknown")
+	assert.Error(t, err)
+}
+
+func TestParseJavaProperties(t *testing.T) {
+	java, err := parseJavaProperties([]byte(javaPropertiesOutput))
+	if assert.NoError(t, err) {
+		assert.Equal(t, &JavaInstallation{
+			Vendor:       "Eclipse Adoptium",
+			Version:      "17.0.2",
+			MajorVersion: 17,
+			Arch:         "amd64",
+		}, java)
+	}
+
+	_, err = parseJavaProperties([]byte("Error: could not create the Java Virtual Machine."))
+	assert.Error(t, err)
+}
+
+func TestCheckJava(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake java is a shell script")
+	}
+
+	w := &Instance{Path: t.TempDir()}
+
+	name := filepath.Join(t.TempDir(), "java")
+	script := fakeJavaScript
+
+	if !assert.NoError(t, os.WriteFile(name, []byte(script), 0755)) {
+		return
+	}
+
+	java, err := w.CheckJava(name, Version{JavaVersion: &JavaVersionRecommendation{MajorVersion: 17}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, name, java.Path)
+		assert.False(t, java.LegacyJavaMismatch(Version{JavaVersion: &JavaVersionRecommendation{MajorVersion: 17}}))
+		assert.True(t, java.LegacyJavaMismatch(Version{}))
+	}
+
+	_, err = w.CheckJava(name, Version{JavaVersion: &JavaVersionRecommendation{MajorVersion: 21}})
+	assert.True(t, errors.Is(err, &JavaVersionMismatchError{Required: 21, Actual: 17}))
+
+	// executable that fails when run, but keeps modification time and size, must be served from cache
+	stat, _ := os.Stat(name)
+	broken := []byte("#!/bin/sh\nexit 1\n")
+	broken = append(broken, make([]byte, len(script)-len(broken))...)
+
+	if !assert.NoError(t, os.WriteFile(name, broken, 0755)) || !assert.NoError(t, os.Chtimes(name, time.Now(), stat.ModTime())) {
+		return
+	}
+
+	_, err = w.ProbeJava(name)
+	assert.NoError(t, err)
+
+	if !assert.NoError(t, os.Chtimes(name, time.Now(), stat.ModTime().Add(time.Second))) {
+		return
+	}
+
+	_, err = w.ProbeJava(name)
+	assert.True(t, errors.Is(err, &JavaProbeError{}))
+}
+
+func TestProbeJavaUncached(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake java is a shell script")
+	}
+
+	name := filepath.Join(t.TempDir(), "java")
+	if !assert.NoError(t, os.WriteFile(name, []byte(fakeJavaScript), 0755)) {
+		return
+	}
+
+	// instance directory cannot be created under the file, so the cache cannot be written
+	w := &Instance{Path: filepath.Join(name, "instance")}
+
+	java, err := w.ProbeJava(name)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 17, java.MajorVersion)
+	}
+}
@@ -2,13 +2,17 @@ package launcher
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
 
 	"github.com/brawaru/marct/network"
-	"github.com/brawaru/marct/validfile"
+	"github.com/brawaru/marct/utils"
 )
 
 // linux (x64) => linux
@@ -74,19 +78,25 @@ func (w *Instance) ReadJREs() (runtimes *JavaRuntimesMap, err error) {
 	return
 }
 
-// FetchJREs checks whether existing Java Runtimes manifest file is not too old, then, if it is old, fetches anew, or
-// otherwise, re-uses existing file, unless force argument is set to true.
+// FetchJREs checks whether existing Java Runtimes manifest file is not too old, then, if it is old, checks whether it
+// has changed and fetches anew, or otherwise, re-uses existing file, unless force argument is set to true.
 func (w *Instance) FetchJREs(force bool) (runtimes *JavaRuntimesMap, err error) {
 	name := filepath.Join(w.jreRuntimesPath(), javaRuntimesManifestName)
 
-	expired := force || validfile.NotExpired(name, javaRuntimesManifestTTL) != nil
+	ttl := javaRuntimesManifestTTL
+	if force {
+		ttl = 0
+	}
 
-	if expired {
-		_, err = network.Download(javaRuntimesURL, name)
+	_, err = network.FetchCached(javaRuntimesURL, name, ttl, network.WithRetries())
+	if err != nil {
+		return
 	}
 
-	if err == nil {
-		return w.ReadJREs()
+	runtimes, err = w.ReadJREs()
+	if err != nil {
+		// otherwise the broken manifest would be renewed by every conditional request
+		_ = network.RemoveCached(name)
 	}
 
 	return
@@ -100,31 +110,276 @@ func (w *Instance) JREPath(version string, selector string) string {
 	return filepath.Join(w.jreRuntimesPath(), version, selector)
 }
 
-func (w *Instance) InstallJRE(runtimes JavaRuntimesMap, version string) error {
-	matching, selector := runtimes.GetMatching()
+// InstallJRE installs the build of the runtime component from the provider, or the most recent build if build is
+// empty. Progress of the download is reported to progress, if it is not nil.
+func (w *Instance) InstallJRE(provider JREProvider, component string, build string, progress func(JREProgress)) error {
+	release, err := provider.Release(component, build)
+	if err != nil {
+		return err
+	}
+
+	w.sweepParts()
 
-	if matching == nil {
-		return &JavaUnavailableError{
+	return release.Install(w.JREPath(component, GetJRESelector()), progress)
+}
+
+// JavaComponent returns the Java runtime component the version needs.
+func JavaComponent(version Version) string {
+	if version.JavaVersion == nil || version.JavaVersion.Component == "" {
+		return legacyJavaComponent
+	}
+
+	return version.JavaVersion.Component
+}
+
+// JREExecutable returns the path to the Java executable of the runtime.
+func (w *Instance) JREExecutable(component string, selector string) string {
+	return javaExecutable(filepath.Join(w.JREPath(component, selector), component))
+}
+
+// InstalledJREVersion returns the installed version of the runtime, or empty string if the runtime is not installed.
+func (w *Instance) InstalledJREVersion(component string, selector string) (string, error) {
+	name := filepath.Join(w.JREPath(component, selector), jreVersionFile)
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		if utils.DoesNotExist(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("read %s: %w", name, err)
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// OpenJRE opens the installed runtime for verification or repair. If the runtime is not installed, JavaUnavailableError
+// is returned. If the runtime has been installed without Mojang's manifest, for example, from Adoptium,
+// JREVerificationUnsupportedError is returned.
+func (w *Instance) OpenJRE(component string) (*JREInstallation, error) {
+	selector := GetJRESelector()
+
+	installed, err := w.InstalledJREVersion(component, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if installed == "" {
+		return nil, &JavaUnavailableError{
 			System:  selector,
-			Version: version,
-			Errno:   ErrSystemUnsupported,
+			Version: component,
+			Errno:   ErrNotInstalled,
 		}
 	}
 
-	desc := matching[version].MostRecent()
+	descriptor := &JavaVersionDescriptor{Version: JavaVersion{Name: installed}}
+	installation := NewInstallation(component, selector, descriptor, w.JREPath(component, selector))
 
-	if desc == nil {
+	if err := installation.readManifest(); err != nil {
+		// Adoptium installation removes the manifest, as its files are not listed anywhere
+		if utils.DoesNotExist(err) {
+			return nil, &JREVerificationUnsupportedError{Component: component}
+		}
+
+		return nil, err
+	}
+
+	return installation, nil
+}
+
+// EnsureJRE installs the runtime if it is missing or differs from the build it is pinned to in the settings, or from
+// the most recent build if it is not pinned, and checks that its Java executable exists. If installation is needed,
+// installing is called with the version to be installed beforehand. Runtime that is already installed is used as is if
+// the provider cannot be reached.
+func (w *Instance) EnsureJRE(component string, settings *Settings, installing func(version string)) error {
+	selector := GetJRESelector()
+	executable := w.JREExecutable(component, selector)
+
+	installed, err := w.InstalledJREVersion(component, selector)
+	if err != nil {
+		return err
+	}
+
+	_, statErr := os.Stat(executable)
+	usable := installed != "" && statErr == nil
+
+	provider, err := w.JREProvider(component, settings.JRE)
+	if err != nil {
+		if usable {
+			return nil
+		}
+
+		return err
+	}
+
+	release, err := provider.Release(component, settings.JavaPin(component))
+	if err != nil {
+		var unavailableErr *JavaUnavailableError
+		if usable && !errors.As(err, &unavailableErr) {
+			return nil
+		}
+
+		return err
+	}
+
+	if !usable || release.Name() != installed {
+		if installing != nil {
+			installing(release.Name())
+		}
+
+		if err := release.Install(w.JREPath(component, selector), nil); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(executable); err != nil {
 		return &JavaUnavailableError{
 			System:  selector,
-			Version: version,
-			Errno:   ErrVersionUnavailable,
+			Version: component,
+			Errno:   ErrExecutableMissing,
 		}
 	}
 
-	installation := NewInstallation(version, selector, desc, w.JREPath(version, selector))
+	return nil
+}
+
+// InstalledJRE describes the runtime installed in the instance.
+type InstalledJRE struct {
+	Component string // Runtime component, e.g. java-runtime-gamma.
+	Selector  string // System the runtime is installed for.
+	Version   string // Installed build, empty if the installation is incomplete.
+	Size      int64  // Size of the installation on disk in bytes.
+	Path      string // Path to the installation.
+}
+
+// dirSize returns the total size of the regular files in the directory, links are not followed.
+func dirSize(dir string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size, err
+}
+
+// InstalledJREs lists the runtimes installed in the instance for all systems, sorted by component and selector.
+func (w *Instance) InstalledJREs() ([]InstalledJRE, error) {
+	root := w.jreRuntimesPath()
+
+	components, err := os.ReadDir(root)
+	if err != nil {
+		if utils.DoesNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read dir %s: %w", root, err)
+	}
+
+	var installed []InstalledJRE
+
+	for _, c := range components {
+		if !c.IsDir() {
+			continue
+		}
+
+		selectors, err := os.ReadDir(filepath.Join(root, c.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read dir %s: %w", filepath.Join(root, c.Name()), err)
+		}
+
+		for _, s := range selectors {
+			if !s.IsDir() {
+				continue
+			}
+
+			version, err := w.InstalledJREVersion(c.Name(), s.Name())
+			if err != nil {
+				return nil, err
+			}
+
+			path := w.JREPath(c.Name(), s.Name())
+
+			size, err := dirSize(path)
+			if err != nil {
+				return nil, fmt.Errorf("measure %s: %w", path, err)
+			}
+
+			installed = append(installed, InstalledJRE{
+				Component: c.Name(),
+				Selector:  s.Name(),
+				Version:   version,
+				Size:      size,
+				Path:      path,
+			})
+		}
+	}
+
+	sort.Slice(installed, func(i, j int) bool {
+		if installed[i].Component != installed[j].Component {
+			return installed[i].Component < installed[j].Component
+		}
+
+		return installed[i].Selector < installed[j].Selector
+	})
+
+	return installed, nil
+}
+
+// UninstallJRE removes the runtime installed for the current system. If a running session uses the runtime,
+// JREInUseError is returned and nothing is removed.
+func (w *Instance) UninstallJRE(component string) error {
+	selector := GetJRESelector()
+	path := w.JREPath(component, selector)
+
+	if _, err := os.Stat(path); err != nil {
+		if utils.DoesNotExist(err) {
+			return &JavaUnavailableError{
+				System:  selector,
+				Version: component,
+				Errno:   ErrNotInstalled,
+			}
+		}
+
+		return fmt.Errorf("stat %s: %w", path, err)
+	}
+
+	sessions, err := w.ReadRunningSessions()
+	if err != nil {
+		return fmt.Errorf("read running sessions: %w", err)
+	}
+
+	for _, s := range sessions {
+		if rel, err := filepath.Rel(path, s.JavaPath); s.JavaPath != "" && err == nil && !strings.HasPrefix(rel, "..") {
+			return &JREInUseError{
+				Component: component,
+				SessionID: s.ID,
+				PID:       s.PID,
+			}
+		}
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("remove %s: %w", path, err)
+	}
 
-	if installErr := installation.Install(); installErr != nil {
-		return fmt.Errorf("cannot install JRE %s (%s): %w", version, selector, installErr)
+	// component directory only holds installations for different systems
+	parent := filepath.Dir(path)
+	if entries, err := os.ReadDir(parent); err == nil && len(entries) == 0 {
+		_ = os.Remove(parent)
 	}
 
 	return nil
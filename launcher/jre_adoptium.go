@@ -0,0 +1,323 @@
+package launcher
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/brawaru/marct/launcher/download"
+	"github.com/brawaru/marct/network"
+	"github.com/brawaru/marct/utils"
+	"github.com/brawaru/marct/utils/archive"
+	"github.com/brawaru/marct/validfile"
+)
+
+// adoptiumJREProvider provides runtimes from the Adoptium API or its mirror.
+type adoptiumJREProvider struct {
+	baseURL  string
+	majors   map[string]int // Major versions of Java for the runtime components.
+	cacheDir string         // Directory the responses of the API are cached in, empty to not cache them.
+}
+
+// adoptiumPackage is the archive of the Adoptium build.
+type adoptiumPackage struct {
+	Name     string `json:"name"`     // File name of the archive.
+	Link     string `json:"link"`     // URL to download the archive from.
+	Checksum string `json:"checksum"` // SHA-256 of the archive.
+	Size     int64  `json:"size"`     // Size of the archive.
+}
+
+// adoptiumReleaseInfo is the release as returned by the Adoptium API.
+type adoptiumReleaseInfo struct {
+	ReleaseName string `json:"release_name"`
+	Binaries    []struct {
+		Package adoptiumPackage `json:"package"`
+	} `json:"binaries"`
+}
+
+// adoptiumJRERelease is a build of the runtime from Adoptium.
+type adoptiumJRERelease struct {
+	component string
+	name      string
+	pkg       adoptiumPackage
+}
+
+// NewAdoptiumJREProvider creates provider installing runtimes from the Adoptium API at the base URL, or the official
+// API if the base URL is empty. Components are mapped to major versions of Java using majors. Responses of the API are
+// cached in the cache directory, unless it is empty.
+func NewAdoptiumJREProvider(baseURL string, majors map[string]int, cacheDir string) JREProvider {
+	if baseURL == "" {
+		baseURL = defaultAdoptiumURL
+	}
+
+	return &adoptiumJREProvider{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		majors:   majors,
+		cacheDir: cacheDir,
+	}
+}
+
+// adoptiumSystem returns the operating system and architecture as named by Adoptium.
+func adoptiumSystem() (string, string, bool) {
+	var os string
+
+	switch runtime.GOOS {
+	case "linux", "windows", "aix", "solaris":
+		os = runtime.GOOS
+	case "darwin":
+		os = "mac"
+	default:
+		return "", "", false
+	}
+
+	arch := map[string]string{
+		"amd64":   "x64",
+		"386":     "x32",
+		"arm64":   "aarch64",
+		"arm":     "arm",
+		"ppc64le": "ppc64le",
+		"ppc64":   "ppc64",
+		"s390x":   "s390x",
+		"riscv64": "riscv64",
+	}[runtime.GOARCH]
+
+	return os, arch, arch != ""
+}
+
+func (p *adoptiumJREProvider) Name() string {
+	return JREProviderAdoptium
+}
+
+func (p *adoptiumJREProvider) Release(component string, build string) (JRERelease, error) {
+	selector := GetJRESelector()
+
+	major, ok := p.majors[component]
+	if !ok {
+		return nil, &JavaUnavailableError{
+			System:  selector,
+			Version: component,
+			Errno:   ErrVersionUnavailable,
+		}
+	}
+
+	goos, arch, ok := adoptiumSystem()
+	if !ok {
+		return nil, &JavaUnavailableError{
+			System:  selector,
+			Version: component,
+			Errno:   ErrSystemUnsupported,
+		}
+	}
+
+	query := url.Values{
+		"architecture": {arch},
+		"heap_size":    {"normal"},
+		"image_type":   {"jre"},
+		"jvm_impl":     {"hotspot"},
+		"os":           {goos},
+		"project":      {"jdk"},
+	}
+
+	var u string
+	if build == "" {
+		query.Set("page_size", "1")
+		query.Set("sort_order", "DESC")
+		u = fmt.Sprintf("%s/v3/assets/feature_releases/%d/ga?%s", p.baseURL, major, query.Encode())
+	} else {
+		u = fmt.Sprintf("%s/v3/assets/release_name/eclipse/%s?%s", p.baseURL, url.PathEscape(build), query.Encode())
+	}
+
+	unavailable := &JavaUnavailableError{
+		System:  selector,
+		Version: component,
+		Errno:   ErrVersionUnavailable,
+	}
+
+	if build != "" {
+		unavailable.Build = build
+		unavailable.Errno = ErrBuildUnavailable
+	}
+
+	body, err := p.get(u)
+	if err != nil {
+		return nil, err
+	}
+
+	if body == nil {
+		return nil, unavailable
+	}
+
+	var release adoptiumReleaseInfo
+
+	if build == "" {
+		var releases []adoptiumReleaseInfo
+		if err := json.Unmarshal(body, &releases); err != nil {
+			return nil, fmt.Errorf("decode releases: %w", err)
+		}
+
+		if len(releases) == 0 {
+			return nil, unavailable
+		}
+
+		release = releases[0]
+	} else if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("decode release: %w", err)
+	}
+
+	for _, b := range release.Binaries {
+		if archive.FormatByName(b.Package.Name) != archive.FormatUnknown {
+			return &adoptiumJRERelease{
+				component: component,
+				name:      release.ReleaseName,
+				pkg:       b.Package,
+			}, nil
+		}
+	}
+
+	return nil, unavailable
+}
+
+// get requests the URL and returns the body of the response, or nil if nothing has been found.
+func (p *adoptiumJREProvider) get(u string) ([]byte, error) {
+	if p.cacheDir != "" {
+		return p.getCached(u)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := network.PerformRequest(req, network.WithRetries(), network.WithStatusCheck())
+	if errors.Is(err, &network.HTTPStatusError{StatusCode: http.StatusNotFound}) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("request %s: %w", u, err)
+	}
+
+	defer utils.DClose(resp.Body)
+
+	return io.ReadAll(resp.Body)
+}
+
+// getCached is like get, but the response is cached and only requested again once it expires.
+func (p *adoptiumJREProvider) getCached(u string) ([]byte, error) {
+	h := sha1.Sum([]byte(u))
+	name := filepath.Join(p.cacheDir, hex.EncodeToString(h[:])+".json")
+
+	_, err := network.FetchCached(u, name, adoptiumResponseTTL, network.WithRetries())
+	if errors.Is(err, &network.HTTPStatusError{StatusCode: http.StatusNotFound}) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", u, err)
+	}
+
+	return os.ReadFile(name)
+}
+
+func (r *adoptiumJRERelease) Name() string {
+	return r.name
+}
+
+func (r *adoptiumJRERelease) Install(dir string, progress func(JREProgress)) error {
+	stagingPath := filepath.Join(dir, r.component+"_staging")
+	filesPath := filepath.Join(dir, r.component)
+	archivePath := filepath.Join(stagingPath, r.pkg.Name)
+
+	// runtime is downloaded as a single archive, so there's no progress until it's complete
+	report := func(done int) {
+		if progress != nil {
+			progress(JREProgress{
+				FilesDone:  done,
+				FilesTotal: 1,
+				BytesDone:  uint64(done) * uint64(r.pkg.Size),
+				BytesTotal: uint64(r.pkg.Size),
+			})
+		}
+	}
+
+	report(0)
+
+	if err := download.FromURL(r.pkg.Link, archivePath, download.WithSHA256(r.pkg.Checksum), download.WithSize(uint64(r.pkg.Size))); err != nil {
+		return fmt.Errorf("download %q to %q: %w", r.pkg.Link, archivePath, err)
+	}
+
+	report(1)
+
+	extracted := filepath.Join(stagingPath, r.component)
+
+	if err := os.RemoveAll(extracted); err != nil {
+		return fmt.Errorf("cannot clean %q: %w", extracted, err)
+	}
+
+	if err := archive.Extract(archivePath, extracted, archive.WithFormat(archive.FormatByName(r.pkg.Name))); err != nil {
+		return fmt.Errorf("cannot extract %q: %w", archivePath, err)
+	}
+
+	home, err := adoptiumJavaHome(extracted)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(filesPath); err != nil {
+		return fmt.Errorf("cannot remove previous installation %q: %w", filesPath, err)
+	}
+
+	if err := os.Rename(home, filesPath); err != nil {
+		return fmt.Errorf("cannot move %q to %q: %w", home, filesPath, err)
+	}
+
+	if err := os.RemoveAll(stagingPath); err != nil {
+		return fmt.Errorf("cannot delete staging directory %q: %w", stagingPath, err)
+	}
+
+	// files left by Mojang installation would make the runtime look like it can be verified with Mojang's manifest
+	for _, name := range []string{".manifest", r.component + ".sha1"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !utils.DoesNotExist(err) {
+			return fmt.Errorf("cannot remove %q: %w", name, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, jreVersionFile), []byte(r.name), 0644); err != nil {
+		return fmt.Errorf("cannot write version file: %w", err)
+	}
+
+	return nil
+}
+
+// adoptiumJavaHome returns the Java home in the extracted archive, which is its only top-level directory, or
+// Contents/Home inside it for macOS bundles.
+func adoptiumJavaHome(extracted string) (string, error) {
+	entries, err := os.ReadDir(extracted)
+	if err != nil {
+		return "", fmt.Errorf("read dir %q: %w", extracted, err)
+	}
+
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return "", fmt.Errorf("archive must contain a single directory, found %d entries", len(entries))
+	}
+
+	home := filepath.Join(extracted, entries[0].Name())
+
+	bundleHome := filepath.Join(home, "Contents", "Home")
+	if exists, err := validfile.DirExists(bundleHome); err != nil {
+		return "", fmt.Errorf("check dir %q: %w", bundleHome, err)
+	} else if exists {
+		return bundleHome, nil
+	}
+
+	return home, nil
+}
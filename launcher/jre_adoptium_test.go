@@ -0,0 +1,186 @@
+package launcher
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/brawaru/marct/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdoptiumJavaHome(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := adoptiumJavaHome(dir); !assert.Error(t, err, "empty archive must be rejected") {
+		return
+	}
+
+	bundle := filepath.Join(dir, "jdk-17.0.8+7-jre", "Contents", "Home")
+	if !assert.NoError(t, os.MkdirAll(bundle, 0755)) {
+		return
+	}
+
+	home, err := adoptiumJavaHome(dir)
+	if assert.NoError(t, err) {
+		assert.Equal(t, bundle, home)
+	}
+}
+
+func TestAdoptiumInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("archive contains POSIX executable")
+	}
+
+	const component = "java-runtime-gamma"
+	const release = "jdk-17.0.8+7"
+
+	var archive bytes.Buffer
+
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+
+	for _, h := range []*tar.Header{
+		{Name: release + "-jre/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: release + "-jre/bin/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: release + "-jre/bin/java", Typeflag: tar.TypeReg, Mode: 0755, Size: 10},
+		{Name: release + "-jre/bin/jre", Typeflag: tar.TypeSymlink, Linkname: "java"},
+	} {
+		if !assert.NoError(t, tw.WriteHeader(h)) {
+			return
+		}
+
+		if h.Size != 0 {
+			_, _ = tw.Write([]byte("#!/bin/sh\n"))
+		}
+	}
+
+	if !assert.NoError(t, tw.Close()) || !assert.NoError(t, gz.Close()) {
+		return
+	}
+
+	checksum := sha256.Sum256(archive.Bytes())
+
+	var requested string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/assets/feature_releases/17/ga":
+			requested = r.URL.RawQuery
+
+			_ = json.NewEncoder(rw).Encode([]map[string]any{{
+				"release_name": release,
+				"binaries": []map[string]any{{
+					"package": map[string]any{
+						"name":     release + ".tar.gz",
+						"link":     "http://" + r.Host + "/" + release + ".tar.gz",
+						"checksum": hex.EncodeToString(checksum[:]),
+						"size":     archive.Len(),
+					},
+				}},
+			}})
+		case "/" + release + ".tar.gz":
+			_, _ = rw.Write(archive.Bytes())
+		default:
+			http.NotFound(rw, r)
+		}
+	}))
+
+	defer srv.Close()
+
+	w := &Instance{Path: t.TempDir()}
+	provider := NewAdoptiumJREProvider(srv.URL, javaComponentMajorVersions, "")
+
+	_, err := provider.Release(component, "jdk-17.0.0+1")
+	assert.ErrorIs(t, err, &JavaUnavailableError{Errno: ErrBuildUnavailable})
+
+	if _, _, ok := adoptiumSystem(); !ok {
+		t.Skip("system is not supported by Adoptium")
+	}
+
+	if !assert.NoError(t, w.InstallJRE(provider, component, "", nil)) {
+		return
+	}
+
+	assert.Contains(t, requested, "image_type=jre")
+
+	selector := GetJRESelector()
+
+	assert.FileExists(t, w.JREExecutable(component, selector))
+
+	if link, err := os.Readlink(filepath.Join(w.JREPath(component, selector), component, "bin", "jre")); assert.NoError(t, err) {
+		assert.Equal(t, "java", link)
+	}
+
+	installed, err := w.InstalledJREVersion(component, selector)
+	if assert.NoError(t, err) {
+		assert.Equal(t, release, installed)
+	}
+
+	assert.NoDirExists(t, filepath.Join(w.JREPath(component, selector), component+"_staging"))
+}
+
+func TestJREProviderFallback(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+
+	manifest, err := json.Marshal(JavaRuntimesMap{
+		GetJRESelector(): JavaVersionsMap{},
+	})
+
+	name := filepath.Join(w.jreRuntimesPath(), javaRuntimesManifestName)
+
+	if !assert.NoError(t, err) || !assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0755)) ||
+		!assert.NoError(t, os.WriteFile(name, manifest, 0644)) {
+		return
+	}
+
+	for settings, expected := range map[JRESettings]string{
+		{}:                              JREProviderAdoptium,
+		{Provider: JREProviderMojang}:   JREProviderMojang,
+		{Provider: JREProviderAdoptium}: JREProviderAdoptium,
+	} {
+		p, err := w.JREProvider("java-runtime-gamma", settings)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, p.Name())
+		}
+	}
+
+	p, err := w.JREProvider("java-runtime-unknown", JRESettings{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, JREProviderMojang, p.Name())
+	}
+
+	_, err = w.JREProvider("java-runtime-gamma", JRESettings{Provider: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestJREProviderFallbackUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	network.SetMirrors([]network.MirrorRule{{Prefix: "https://launchermeta.mojang.com/", Mirrors: []string{srv.URL + "/"}, SkipOrigin: true}})
+	defer network.SetMirrors(nil)
+
+	w := &Instance{Path: t.TempDir()}
+
+	// list of Mojang runtimes cannot be retrieved
+	p, err := w.JREProvider("java-runtime-gamma", JRESettings{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, JREProviderAdoptium, p.Name())
+	}
+
+	_, err = w.JREProvider("java-runtime-gamma", JRESettings{Provider: JREProviderMojang})
+	assert.Error(t, err)
+
+	_, err = w.JREProvider("java-runtime-unknown", JRESettings{})
+	assert.Error(t, err)
+}
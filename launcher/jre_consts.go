@@ -10,3 +10,29 @@ const javaRuntimesManifestName = "runtimes.json"
 
 // javaRuntimesManifestTTL is the time after which the Java runtimes manifest should be re-fetched.
 const javaRuntimesManifestTTL = time.Hour * 1
+
+// jreDownloadConcurrency is the number of runtime files downloaded at once.
+const jreDownloadConcurrency = 8
+
+// jreMappingSuffix is appended to the name of the staged object while it's being unpacked to the runtime directory.
+const jreMappingSuffix = ".mapping"
+
+// legacyJavaComponent is the Java runtime component used by versions that do not specify one.
+const legacyJavaComponent = "jre-legacy"
+
+// jreVersionFile is the name of the file in the runtime directory storing the installed version of the runtime.
+const jreVersionFile = ".version"
+
+// defaultAdoptiumURL is the base URL of the Adoptium API used when no mirror is configured.
+const defaultAdoptiumURL = "https://api.adoptium.net"
+
+// javaComponentMajorVersions maps Mojang runtime components to major versions of Java they provide. It's used to find
+// a substitute runtime from another provider when Mojang does not provide the component for the system.
+var javaComponentMajorVersions = map[string]int{
+	"jre-legacy":                  8,
+	"java-runtime-alpha":          16,
+	"java-runtime-beta":           17,
+	"java-runtime-gamma":          17,
+	"java-runtime-gamma-snapshot": 17,
+	"java-runtime-delta":          21,
+}
@@ -8,11 +8,15 @@ const (
 	_                                          = iota
 	ErrSystemUnsupported  javaUnavailableErrno = iota
 	ErrVersionUnavailable                      = iota
+	ErrExecutableMissing                       = iota
+	ErrNotInstalled                            = iota
+	ErrBuildUnavailable                        = iota
 )
 
 type JavaUnavailableError struct {
 	System  string
 	Version string
+	Build   string // Build of the version, only set for ErrBuildUnavailable.
 	Errno   javaUnavailableErrno
 }
 
@@ -22,6 +26,12 @@ func (j *JavaUnavailableError) Error() (text string) {
 		text = fmt.Sprintf("no runtimes support system \"%s\"", j.System)
 	case ErrVersionUnavailable:
 		text = fmt.Sprintf("version \"%s\" is not supported on system \"%s\"", j.Version, j.System)
+	case ErrBuildUnavailable:
+		text = fmt.Sprintf("build \"%s\" of version \"%s\" is not available on system \"%s\"", j.Build, j.Version, j.System)
+	case ErrNotInstalled:
+		text = fmt.Sprintf("version \"%s\" for system \"%s\" is not installed", j.Version, j.System)
+	case ErrExecutableMissing:
+		text = fmt.Sprintf("version \"%s\" for system \"%s\" is installed without java executable", j.Version, j.System)
 	}
 
 	return
@@ -33,5 +43,37 @@ func (j *JavaUnavailableError) Is(target error) bool {
 	return ok &&
 		(t.System == "" || j.System == t.System) &&
 		(t.Version == "" || j.Version == t.Version) &&
+		(t.Build == "" || j.Build == t.Build) &&
 		(t.Errno == 0 || j.Errno == t.Errno)
 }
+
+// JREInUseError is returned when the runtime cannot be removed because a running session uses it.
+type JREInUseError struct {
+	Component string // Runtime component.
+	SessionID string // Identifier of the session using the runtime.
+	PID       int    // Process ID of the session.
+}
+
+func (e *JREInUseError) Error() string {
+	return fmt.Sprintf("runtime %s is used by session %s (process %d)", e.Component, e.SessionID, e.PID)
+}
+
+func (e *JREInUseError) Is(target error) bool {
+	t, ok := target.(*JREInUseError)
+	return ok && (t.Component == "" || e.Component == t.Component)
+}
+
+// JREVerificationUnsupportedError is returned when the runtime cannot be verified or repaired, because it has been
+// installed by the provider that does not supply checksums of its files, like Adoptium.
+type JREVerificationUnsupportedError struct {
+	Component string // Runtime component.
+}
+
+func (e *JREVerificationUnsupportedError) Error() string {
+	return fmt.Sprintf("runtime %s cannot be verified, its provider does not supply checksums of files", e.Component)
+}
+
+func (e *JREVerificationUnsupportedError) Is(target error) bool {
+	t, ok := target.(*JREVerificationUnsupportedError)
+	return ok && (t.Component == "" || e.Component == t.Component)
+}
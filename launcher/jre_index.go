@@ -0,0 +1,173 @@
+package launcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brawaru/marct/utils"
+	"github.com/brawaru/marct/utils/osfile"
+)
+
+// jreIndexSeparator separates path of the file from its hash in the JRE index. It's chosen to be a sequence that is
+// unlikely to appear in a path.
+const jreIndexSeparator = " /#// "
+
+// JREIndexEntry is an entry of the JRE index file ({classifier}.sha1), describing a file of the runtime as it was when
+// it has been installed.
+type JREIndexEntry struct {
+	Path    string    // Path to the file relative to the runtime files directory, with forward slashes.
+	SHA1    string    // SHA-1 hash of the file.
+	ModTime time.Time // Modification time of the file, zero if unknown.
+}
+
+// ParseJREIndex parses the JRE index. Each line of the index has format "{path} /#// {sha1} {mtime}", where mtime is
+// modification time of the file in nanoseconds since Unix epoch. Modification time may be omitted.
+func ParseJREIndex(r io.Reader) ([]JREIndexEntry, error) {
+	var entries []JREIndexEntry
+
+	s := bufio.NewScanner(r)
+	line := 0
+
+	for s.Scan() {
+		line++
+
+		text := s.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		p, rest, ok := strings.Cut(text, jreIndexSeparator)
+		if !ok || p == "" {
+			return nil, fmt.Errorf("line %d: missing separator", line)
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected hash and optional modification time", line)
+		}
+
+		entry := JREIndexEntry{
+			Path: p,
+			SHA1: fields[0],
+		}
+
+		if len(fields) == 2 {
+			ns, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid modification time: %w", line, err)
+			}
+
+			entry.ModTime = time.Unix(0, ns)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// WriteJREIndex writes the JRE index entries sorted by path in format read by ParseJREIndex.
+func WriteJREIndex(w io.Writer, entries []JREIndexEntry) error {
+	sorted := make([]JREIndexEntry, len(entries))
+	copy(sorted, entries)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	bw := bufio.NewWriter(w)
+
+	for _, e := range sorted {
+		line := e.Path + jreIndexSeparator + e.SHA1
+		if !e.ModTime.IsZero() {
+			line += " " + strconv.FormatInt(e.ModTime.UnixNano(), 10)
+		}
+
+		if _, err := bw.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// indexPath returns the path to the index file of the installation.
+func (i *JREInstallation) indexPath() string {
+	return filepath.Join(i.Path, i.Classifier+".sha1")
+}
+
+// readIndex reads the index of the installation mapped by the file path. Missing index is treated as empty.
+func (i *JREInstallation) readIndex() (map[string]JREIndexEntry, error) {
+	name := i.indexPath()
+
+	f, err := os.Open(name)
+	if err != nil {
+		if utils.DoesNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+
+	defer utils.DClose(f)
+
+	entries, err := ParseJREIndex(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+
+	index := make(map[string]JREIndexEntry, len(entries))
+	for _, e := range entries {
+		index[e.Path] = e
+	}
+
+	return index, nil
+}
+
+// writeIndex writes the index of all files of the installation that are ready.
+func (i *JREInstallation) writeIndex() error {
+	var entries []JREIndexEntry
+
+	for fp, object := range i.objects {
+		if !object.Type.IsFile() || object.State != FileStateReady {
+			continue
+		}
+
+		stat, err := os.Stat(object.Destination)
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", object.Destination, err)
+		}
+
+		entries = append(entries, JREIndexEntry{
+			Path:    fp,
+			SHA1:    object.Downloads["raw"].SHA1,
+			ModTime: stat.ModTime(),
+		})
+	}
+
+	name := i.indexPath()
+
+	f, err := osfile.New(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+
+	defer utils.DClose(f)
+
+	if err := WriteJREIndex(f, entries); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+
+	return nil
+}
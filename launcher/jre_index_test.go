@@ -0,0 +1,102 @@
+package launcher
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brawaru/marct/launcher/java"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJREIndexRoundTrip(t *testing.T) {
+	entries := []JREIndexEntry{
+		{Path: "release", SHA1: "da39a3ee5e6b4b0d3255bfef95601890afd80709", ModTime: time.Unix(0, 1648475574123456789)},
+		{Path: "bin/java", SHA1: "4d7c3d0e1f5e4a0c3c2e1f8e0c6e1d1b3f2a1c0d"},
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, WriteJREIndex(&buf, entries)) {
+		return
+	}
+
+	assert.Equal(t, "bin/java /#// 4d7c3d0e1f5e4a0c3c2e1f8e0c6e1d1b3f2a1c0d\n"+
+		"release /#// da39a3ee5e6b4b0d3255bfef95601890afd80709 1648475574123456789\n", buf.String())
+
+	parsed, err := ParseJREIndex(&buf)
+	if assert.NoError(t, err) && assert.Len(t, parsed, 2) {
+		assert.Equal(t, entries[1], parsed[0])
+		assert.Equal(t, entries[0].Path, parsed[1].Path)
+		assert.True(t, entries[0].ModTime.Equal(parsed[1].ModTime))
+	}
+
+	_, err = ParseJREIndex(strings.NewReader("bin/java 4d7c3d0e\n"))
+	assert.Error(t, err)
+}
+
+func TestJREInstallationVerify(t *testing.T) {
+	content := []byte("#!/bin/sh\n")
+	sum := sha1.Sum(content)
+
+	manifest := JavaManifest{
+		Files: map[string]JavaFile{
+			"bin": {Type: java.TypeDir},
+			"bin/java": {
+				Type:       java.TypeFile,
+				Executable: true,
+				Downloads: map[string]Download{
+					"raw": {SHA1: hex.EncodeToString(sum[:]), Size: uint64(len(content))},
+				},
+			},
+		},
+	}
+
+	dir := t.TempDir()
+	i := NewInstallation("java-runtime-gamma", GetJRESelector(), nil, dir)
+	executable := filepath.Join(i.filesPath, "bin", "java")
+
+	b, err := json.Marshal(manifest)
+	if !assert.NoError(t, err) ||
+		!assert.NoError(t, os.WriteFile(filepath.Join(dir, ".manifest"), b, 0644)) ||
+		!assert.NoError(t, os.MkdirAll(filepath.Dir(executable), 0755)) ||
+		!assert.NoError(t, os.WriteFile(executable, content, 0755)) {
+		return
+	}
+
+	bad, err := i.Verify(true)
+	if assert.NoError(t, err) && assert.Empty(t, bad) {
+		assert.NoError(t, i.writeIndex())
+	}
+
+	// same size and modification time, but different content, is only noticed by full verification
+	stat, _ := os.Stat(executable)
+	if !assert.NoError(t, os.WriteFile(executable, []byte("#!/bin/zs\n"), 0755)) ||
+		!assert.NoError(t, os.Chtimes(executable, time.Now(), stat.ModTime())) {
+		return
+	}
+
+	bad, err = i.Verify(false)
+	if assert.NoError(t, err) {
+		assert.Empty(t, bad)
+	}
+
+	bad, err = i.Verify(true)
+	if assert.NoError(t, err) && assert.Len(t, bad, 1) {
+		assert.Equal(t, FileStateCorrupted, bad[0].State)
+	}
+
+	if !assert.NoError(t, os.Remove(executable)) {
+		return
+	}
+
+	bad, err = i.Verify(false)
+	if assert.NoError(t, err) && assert.Len(t, bad, 1) {
+		assert.Equal(t, FileStateNotDownloaded, bad[0].State)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 
 	"github.com/brawaru/marct/launcher/download"
 	"github.com/brawaru/marct/launcher/java"
@@ -37,12 +38,21 @@ type JREObject struct {
 	IsRaw             bool      // Whether the file stores is raw or compressed.
 }
 
+// JREProgress describes the progress of downloading the runtime files.
+type JREProgress struct {
+	FilesDone  int    // Number of files downloaded so far.
+	FilesTotal int    // Number of files to download.
+	BytesDone  uint64 // Size of the files downloaded so far.
+	BytesTotal uint64 // Size of all files to download.
+}
+
 type JREInstallation struct {
 	Classifier string
 	Selector   string
 	Descriptor *JavaVersionDescriptor
 	Manifest   *JavaManifest
-	Path       string // Root path of the installation
+	Path       string            // Root path of the installation
+	Progress   func(JREProgress) // Called as files are downloaded, never concurrently. Optional.
 
 	stagingPath string                // Path to the staging directory where all the objects are downloaded
 	objects     map[string]*JREObject // All objects in the installation
@@ -75,6 +85,13 @@ func (i *JREInstallation) fetchManifest() error {
 		return fmt.Errorf("download %q to %q: %w", i.Descriptor.Manifest.URL, dest, err)
 	}
 
+	return i.readManifest()
+}
+
+// readManifest reads the manifest downloaded during installation.
+func (i *JREInstallation) readManifest() error {
+	dest := filepath.Join(i.Path, ".manifest")
+
 	if bytes, err := os.ReadFile(dest); err != nil {
 		return fmt.Errorf("read manifest file %q: %w", dest, err)
 	} else if err := json.Unmarshal(bytes, &i.Manifest); err != nil {
@@ -209,34 +226,69 @@ func (i *JREInstallation) deleteCorrupted() error {
 	return nil
 }
 
+// chooseDownload returns the download of the file, preferring the LZMA-compressed variant when it is smaller.
+func chooseDownload(object *JREObject) (dl Download, isRaw bool, ok bool) {
+	rawDl, hasRaw := object.Downloads["raw"]
+	lzmaDl, hasLzma := object.Downloads["lzma"]
+
+	switch {
+	case hasLzma && (!hasRaw || lzmaDl.Size < rawDl.Size):
+		return lzmaDl, false, true
+	case hasRaw:
+		return rawDl, true, true
+	default:
+		return Download{}, false, false
+	}
+}
+
 func (i *JREInstallation) downloadFiles() error {
+	var downloads []*download.Download
+
 	for fp, object := range i.objects {
-		if !object.Type.IsFile() {
+		if !object.Type.IsFile() || object.State == FileStateReady {
 			continue
 		}
 
-		var objectDl Download
-
-		isRaw := false
-
-		if lzmaDl, hasLzma := object.Downloads["lzma"]; hasLzma {
-			objectDl = lzmaDl
-		} else if rawDl, hasRaw := object.Downloads["raw"]; hasRaw {
-			objectDl = rawDl
-			isRaw = true
-		} else {
+		objectDl, isRaw, ok := chooseDownload(object)
+		if !ok {
 			return fmt.Errorf("file %q has no downloads?! O_o", fp)
 		}
 
+		// files with the same contents share the staging file, which manager downloads once
 		dest := filepath.Join(i.stagingPath, objectDl.SHA1)
 
-		if err := download.FromURL(objectDl.URL, dest, download.WithSHA1(objectDl.SHA1)); err != nil {
+		d, err := download.NewURL(objectDl.URL, dest, download.WithSHA1(objectDl.SHA1), download.WithSize(objectDl.Size))
+		if err != nil {
 			return fmt.Errorf("download %q to %q: %w", objectDl.URL, fp, err)
 		}
 
+		downloads = append(downloads, d)
+
 		object.IsRaw = isRaw
 		object.ObjectDestination = dest
-		object.State = FileStateDownloaded
+	}
+
+	manager := download.NewManager(jreDownloadConcurrency)
+
+	if i.Progress != nil {
+		manager.Subscribe(func(p download.Progress) {
+			i.Progress(JREProgress{
+				FilesDone:  p.FilesDone,
+				FilesTotal: p.FilesTotal,
+				BytesDone:  p.BytesDone,
+				BytesTotal: p.BytesTotal,
+			})
+		})
+	}
+
+	if err := manager.Run(downloads); err != nil {
+		return err
+	}
+
+	for _, object := range i.objects {
+		if object.ObjectDestination != "" && object.State != FileStateReady {
+			object.State = FileStateDownloaded
+		}
 	}
 
 	return nil
@@ -283,6 +335,8 @@ func (i *JREInstallation) mapLink(fp string, object *JREObject) error {
 	return nil
 }
 
+// mapFile unpacks the downloaded object to its destination. The file is prepared in the staging directory and moved in
+// place once it's complete, so interrupted mapping never leaves a partially written file behind.
 func (i *JREInstallation) mapFile(_ string, object *JREObject) error {
 	if dir := filepath.Dir(object.Destination); dir != "." {
 		if mkdirErr := os.MkdirAll(dir, 0755); mkdirErr != nil {
@@ -290,16 +344,37 @@ func (i *JREInstallation) mapFile(_ string, object *JREObject) error {
 		}
 	}
 
-	file, createErr := os.Create(object.Destination)
+	tmp := object.ObjectDestination + jreMappingSuffix
+
+	if err := i.unpackObject(object, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, object.Destination); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("cannot move %q to %q: %w", tmp, object.Destination, err)
+	}
+
+	return nil
+}
+
+// unpackObject decompresses the downloaded object to the file, validates it and marks it executable if needed.
+func (i *JREInstallation) unpackObject(object *JREObject, dest string) error {
+	file, createErr := os.Create(dest)
 	if createErr != nil {
-		return fmt.Errorf("cannot create file %q: %w", object.Destination, createErr)
+		return fmt.Errorf("cannot create file %q: %w", dest, createErr)
 	}
 
+	defer utils.DClose(file)
+
 	objectFile, openErr := os.Open(object.ObjectDestination)
 	if openErr != nil {
 		return fmt.Errorf("cannot open file %q: %w", object.ObjectDestination, openErr)
 	}
 
+	defer utils.DClose(objectFile)
+
 	srcReader := io.Reader(objectFile)
 
 	if !object.IsRaw {
@@ -307,23 +382,24 @@ func (i *JREInstallation) mapFile(_ string, object *JREObject) error {
 	}
 
 	if _, copyErr := io.Copy(file, srcReader); copyErr != nil {
-		return fmt.Errorf("cannot copy %q to %q: %w", object.ObjectDestination, object.Destination, copyErr)
+		return fmt.Errorf("cannot copy %q to %q: %w", object.ObjectDestination, dest, copyErr)
 	}
 
-	utils.DClose(objectFile)
-	utils.DClose(file)
+	if syncErr := file.Sync(); syncErr != nil {
+		return fmt.Errorf("cannot sync file %q: %w", dest, syncErr)
+	}
 
 	rawDl := object.Downloads["raw"]
 
-	if validateErr := validfile.ValidateFileHex(object.Destination, sha1.New(), rawDl.SHA1); validateErr != nil {
+	if validateErr := validfile.ValidateFileHex(dest, sha1.New(), rawDl.SHA1); validateErr != nil {
 		return validateErr
 	}
 
 	if object.Executable {
-		if stat, statErr := os.Stat(object.Destination); statErr != nil {
-			return fmt.Errorf("cannot stat file %q: %w", object.Destination, statErr)
-		} else if chmodErr := os.Chmod(object.Destination, stat.Mode()|0b1000000); chmodErr != nil {
-			return fmt.Errorf("cannot mark file %q as executable: %w", object.Destination, chmodErr)
+		if stat, statErr := file.Stat(); statErr != nil {
+			return fmt.Errorf("cannot stat file %q: %w", dest, statErr)
+		} else if chmodErr := file.Chmod(stat.Mode() | 0b1000000); chmodErr != nil {
+			return fmt.Errorf("cannot mark file %q as executable: %w", dest, chmodErr)
 		}
 	}
 
@@ -374,7 +450,7 @@ func (i *JREInstallation) deleteStaging() error {
 }
 
 func (i *JREInstallation) writeVersion() error {
-	versionFile, createErr := os.Create(filepath.Join(i.Path, ".version"))
+	versionFile, createErr := os.Create(filepath.Join(i.Path, jreVersionFile))
 
 	if createErr == nil {
 		defer utils.DClose(versionFile)
@@ -412,7 +488,7 @@ func (i *JREInstallation) Install() error {
 	if allValid, err := i.validateObjects(); err != nil {
 		return fmt.Errorf("cannot validate objects: %w", err)
 	} else if allValid {
-		return nil
+		return i.writeIndex()
 	}
 
 	// 2. delete corrupted ones
@@ -457,5 +533,89 @@ func (i *JREInstallation) Install() error {
 		return &PostValidationError{faulty}
 	}
 
+	// 7. write index for fast verification
+
+	if err := i.writeIndex(); err != nil {
+		return fmt.Errorf("cannot write index: %w", err)
+	}
+
 	return nil
 }
+
+// matchesIndex checks whether the file is unchanged since it has been recorded in the index, by comparing its size and
+// modification time, without hashing it.
+func (i *JREInstallation) matchesIndex(fp string, object *JREObject, index map[string]JREIndexEntry) bool {
+	entry, ok := index[fp]
+	if !ok {
+		return false
+	}
+
+	rawDl := object.Downloads["raw"]
+	if entry.SHA1 != rawDl.SHA1 || entry.ModTime.IsZero() {
+		return false
+	}
+
+	stat, err := os.Stat(object.Destination)
+	if err != nil || !stat.Mode().IsRegular() {
+		return false
+	}
+
+	return uint64(stat.Size()) == rawDl.Size && stat.ModTime().Equal(entry.ModTime)
+}
+
+// Verify checks the installed files against the manifest and returns objects that are missing or corrupted. Unless full
+// is set, files whose size and modification time match the index are trusted without hashing them.
+func (i *JREInstallation) Verify(full bool) ([]JREObject, error) {
+	if i.Manifest == nil {
+		if err := i.readManifest(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := i.prepareObjects(); err != nil {
+		return nil, fmt.Errorf("cannot prepare objects: %w", err)
+	}
+
+	var index map[string]JREIndexEntry
+
+	if !full {
+		var err error
+		if index, err = i.readIndex(); err != nil {
+			return nil, err
+		}
+	}
+
+	var bad []JREObject
+
+	for fp, object := range i.objects {
+		if object.Type.IsFile() && i.matchesIndex(fp, object, index) {
+			object.State = FileStateReady
+			continue
+		}
+
+		if err := i.validateObject(fp, object); err != nil {
+			return nil, fmt.Errorf("failed to validate object %q: %w", fp, err)
+		}
+
+		if object.State != FileStateReady {
+			bad = append(bad, *object)
+		}
+	}
+
+	sort.Slice(bad, func(a, b int) bool {
+		return bad[a].Destination < bad[b].Destination
+	})
+
+	return bad, nil
+}
+
+// Repair re-downloads missing and corrupted files of the installation using the manifest it has been installed from.
+func (i *JREInstallation) Repair() error {
+	if i.Manifest == nil {
+		if err := i.readManifest(); err != nil {
+			return err
+		}
+	}
+
+	return i.Install()
+}
@@ -0,0 +1,99 @@
+package launcher
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/brawaru/marct/launcher/java"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChooseDownload(t *testing.T) {
+	raw := Download{SHA1: "raw", Size: 100}
+	small := Download{SHA1: "lzma", Size: 40}
+	large := Download{SHA1: "lzma", Size: 120}
+
+	for _, c := range []struct {
+		downloads map[string]Download
+		expected  Download
+		isRaw     bool
+	}{
+		{map[string]Download{"raw": raw, "lzma": small}, small, false},
+		{map[string]Download{"raw": raw, "lzma": large}, raw, true},
+		{map[string]Download{"lzma": large}, large, false},
+		{map[string]Download{"raw": raw}, raw, true},
+	} {
+		dl, isRaw, ok := chooseDownload(&JREObject{JavaFile: JavaFile{Downloads: c.downloads}})
+		if assert.True(t, ok) {
+			assert.Equal(t, c.expected, dl)
+			assert.Equal(t, c.isRaw, isRaw)
+		}
+	}
+
+	_, _, ok := chooseDownload(&JREObject{})
+	assert.False(t, ok)
+}
+
+func TestJREInstallationInstall(t *testing.T) {
+	content := []byte("#!/bin/sh\n")
+	sum := sha1.Sum(content)
+	hash := hex.EncodeToString(sum[:])
+
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = rw.Write(content)
+	}))
+
+	defer srv.Close()
+
+	file := JavaFile{
+		Type:       java.TypeFile,
+		Executable: true,
+		Downloads: map[string]Download{
+			"raw": {SHA1: hash, Size: uint64(len(content)), URL: srv.URL + "/java"},
+		},
+	}
+
+	dir := t.TempDir()
+	i := NewInstallation("java-runtime-gamma", GetJRESelector(), &JavaVersionDescriptor{Version: JavaVersion{Name: "17.0.1"}}, dir)
+	i.Manifest = &JavaManifest{
+		Files: map[string]JavaFile{
+			"bin":      {Type: java.TypeDir},
+			"bin/java": file,
+			"bin/jre":  file,
+		},
+	}
+
+	var events []JREProgress
+	i.Progress = func(p JREProgress) {
+		events = append(events, p)
+	}
+
+	if !assert.NoError(t, i.Install()) {
+		return
+	}
+
+	assert.Equal(t, int32(1), requests, "files with the same contents must be downloaded once")
+
+	if assert.NotEmpty(t, events) {
+		assert.Equal(t, JREProgress{FilesTotal: 1, BytesTotal: uint64(len(content))}, events[0])
+		assert.Equal(t, JREProgress{FilesDone: 1, FilesTotal: 1, BytesDone: uint64(len(content)), BytesTotal: uint64(len(content))}, events[len(events)-1])
+	}
+
+	assert.FileExists(t, filepath.Join(i.filesPath, "bin", "jre"))
+	assert.NoDirExists(t, i.stagingPath)
+
+	// files are unpacked in the staging directory, so nothing else is left next to them
+	entries, err := os.ReadDir(filepath.Join(i.filesPath, "bin"))
+	if assert.NoError(t, err) {
+		assert.Len(t, entries, 2)
+	}
+}
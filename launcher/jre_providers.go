@@ -0,0 +1,180 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/brawaru/marct/globstate"
+)
+
+// Names of the JRE providers, as used in JRESettings.Provider.
+const (
+	JREProviderMojang   = "mojang"
+	JREProviderAdoptium = "adoptium"
+)
+
+// JRERelease is a build of the runtime component that can be installed.
+type JRERelease interface {
+	// Name returns the name of the build, which is written to the .version file.
+	Name() string
+	// Install installs the build to the path, which is the runtime directory for the component and the system. Progress
+	// of the download is reported to progress, if it is not nil.
+	Install(path string, progress func(JREProgress)) error
+}
+
+// JREProvider finds builds of the runtime components to install.
+type JREProvider interface {
+	// Name returns the name of the provider.
+	Name() string
+	// Release returns the build of the component, or the most recent build if build is empty. If the provider has no
+	// such build for the current system, JavaUnavailableError is returned.
+	Release(component string, build string) (JRERelease, error)
+}
+
+// mojangJREProvider provides runtimes listed in the Mojang's Java runtimes manifest.
+type mojangJREProvider struct {
+	runtimes JavaRuntimesMap
+}
+
+// mojangJRERelease is a build of the runtime described by the Mojang's manifest.
+type mojangJRERelease struct {
+	component  string
+	selector   string
+	descriptor *JavaVersionDescriptor
+}
+
+// NewMojangJREProvider creates provider installing runtimes from the Mojang's Java runtimes manifest.
+func NewMojangJREProvider(runtimes JavaRuntimesMap) JREProvider {
+	return &mojangJREProvider{runtimes: runtimes}
+}
+
+func (p *mojangJREProvider) Name() string {
+	return JREProviderMojang
+}
+
+func (p *mojangJREProvider) Release(component string, build string) (JRERelease, error) {
+	matching, selector := p.runtimes.GetMatching()
+
+	if matching == nil {
+		return nil, &JavaUnavailableError{
+			System:  selector,
+			Version: component,
+			Errno:   ErrSystemUnsupported,
+		}
+	}
+
+	desc := selectJREBuild(matching[component], build)
+
+	if desc == nil {
+		if build != "" && len(matching[component]) != 0 {
+			return nil, &JavaUnavailableError{
+				System:  selector,
+				Version: component,
+				Build:   build,
+				Errno:   ErrBuildUnavailable,
+			}
+		}
+
+		return nil, &JavaUnavailableError{
+			System:  selector,
+			Version: component,
+			Errno:   ErrVersionUnavailable,
+		}
+	}
+
+	return &mojangJRERelease{
+		component:  component,
+		selector:   selector,
+		descriptor: desc,
+	}, nil
+}
+
+// provides checks whether the manifest lists any build of the component for the current system.
+func (p *mojangJREProvider) provides(component string) bool {
+	matching, _ := p.runtimes.GetMatching()
+	return len(matching[component]) != 0
+}
+
+func (r *mojangJRERelease) Name() string {
+	return r.descriptor.Version.Name
+}
+
+func (r *mojangJRERelease) Install(path string, progress func(JREProgress)) error {
+	installation := NewInstallation(r.component, r.selector, r.descriptor, path)
+	installation.Progress = progress
+
+	if err := installation.Install(); err != nil {
+		return fmt.Errorf("cannot install JRE %s (%s): %w", r.component, r.selector, err)
+	}
+
+	return nil
+}
+
+// selectJREBuild returns the descriptor of the build, or the most recent one if build is empty.
+func selectJREBuild(descriptors JavaVersionDescriptors, build string) *JavaVersionDescriptor {
+	if build == "" {
+		return descriptors.MostRecent()
+	}
+
+	return descriptors.Get(build)
+}
+
+// JREProvider chooses the provider to install the runtime component from. Unless the provider is set in the settings,
+// Mojang is preferred, and Adoptium is used for systems or components that Mojang does not provide, or when the list of
+// runtimes provided by Mojang cannot be retrieved, if the major version of Java for the component is known.
+func (w *Instance) JREProvider(component string, settings JRESettings) (JREProvider, error) {
+	adoptium := func() JREProvider {
+		return NewAdoptiumJREProvider(settings.AdoptiumURL, javaComponentMajorVersions, w.adoptiumCachePath())
+	}
+
+	switch settings.Provider {
+	case JREProviderAdoptium:
+		return adoptium(), nil
+	case "", JREProviderMojang:
+	default:
+		return nil, fmt.Errorf("unknown JRE provider %q", settings.Provider)
+	}
+
+	_, known := javaComponentMajorVersions[component]
+
+	runtimes, err := w.FetchJREs(false)
+	if err != nil {
+		// whether Mojang provides the component cannot be told, but Adoptium can still be used
+		if settings.Provider == "" && known {
+			if globstate.VerboseLogs {
+				fmt.Fprintf(os.Stderr, "cannot retrieve a list of JREs from Mojang, using Adoptium: %s\n", err)
+			}
+
+			return adoptium(), nil
+		}
+
+		return nil, fmt.Errorf("cannot retrieve a list of available JREs: %w", err)
+	}
+
+	mojang := &mojangJREProvider{runtimes: *runtimes}
+
+	if settings.Provider == JREProviderMojang || mojang.provides(component) {
+		return mojang, nil
+	}
+
+	if known {
+		return adoptium(), nil
+	}
+
+	return mojang, nil
+}
+
+// KnownJavaComponents returns sorted names of the runtime components whose major version of Java is known, and which
+// can therefore be installed from providers other than Mojang.
+func KnownJavaComponents() []string {
+	components := make([]string, 0, len(javaComponentMajorVersions))
+
+	for component := range javaComponentMajorVersions {
+		components = append(components, component)
+	}
+
+	sort.Strings(components)
+
+	return components
+}
@@ -0,0 +1,155 @@
+package launcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brawaru/marct/sdtypes"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJavaComponent(t *testing.T) {
+	assert.Equal(t, "jre-legacy", JavaComponent(Version{}))
+	assert.Equal(t, "java-runtime-gamma", JavaComponent(Version{
+		JavaVersion: &JavaVersionRecommendation{Component: "java-runtime-gamma", MajorVersion: 17},
+	}))
+}
+
+func TestEnsureJREUpToDate(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+
+	const component = "java-runtime-gamma"
+	selector := GetJRESelector()
+
+	// fresh manifest listing the installed version, so nothing is downloaded
+	manifest, err := json.Marshal(JavaRuntimesMap{
+		selector: JavaVersionsMap{
+			component: JavaVersionDescriptors{
+				{Version: JavaVersion{Name: "17.0.1", Released: sdtypes.RFC3339Time(time.Unix(1634000000, 0))}},
+				{Version: JavaVersion{Name: "17.0.1.12.1", Released: sdtypes.RFC3339Time(time.Unix(1640000000, 0))}},
+			},
+		},
+	})
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	executable := w.JREExecutable(component, selector)
+
+	for name, content := range map[string][]byte{
+		filepath.Join(w.jreRuntimesPath(), javaRuntimesManifestName):  manifest,
+		filepath.Join(w.JREPath(component, selector), jreVersionFile): []byte("17.0.1.12.1"),
+	} {
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0755)) || !assert.NoError(t, os.WriteFile(name, content, 0644)) {
+			return
+		}
+	}
+
+	if !assert.NoError(t, os.MkdirAll(filepath.Dir(executable), 0755)) || !assert.NoError(t, os.WriteFile(executable, nil, 0755)) {
+		return
+	}
+
+	var installing string
+
+	settings := &Settings{}
+
+	assert.NoError(t, w.EnsureJRE(component, settings, func(v string) { installing = v }))
+	assert.Empty(t, installing, "up to date runtime must not be reinstalled")
+
+	if !assert.NoError(t, os.WriteFile(filepath.Join(w.JREPath(component, selector), jreVersionFile), []byte("17.0.1"), 0644)) {
+		return
+	}
+
+	settings.SetJavaPin(component, "17.0.1")
+
+	assert.NoError(t, w.EnsureJRE(component, settings, func(v string) { installing = v }))
+	assert.Empty(t, installing, "runtime matching the pin must not be reinstalled")
+
+	settings.SetJavaPin(component, "")
+
+	// descriptor has no manifest to download, so installation fails after the callback
+	assert.Error(t, w.EnsureJRE(component, settings, func(v string) { installing = v }))
+	assert.Equal(t, "17.0.1.12.1", installing, "outdated runtime must be reinstalled")
+
+	runtimes, err := w.ReadJREs()
+	if assert.NoError(t, err) {
+		err = w.InstallJRE(NewMojangJREProvider(*runtimes), component, "17.0.0", nil)
+		assert.ErrorIs(t, err, &JavaUnavailableError{Build: "17.0.0", Errno: ErrBuildUnavailable})
+	}
+}
+
+func TestUninstallJREInUse(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+
+	const component = "java-runtime-gamma"
+	executable := w.JREExecutable(component, GetJRESelector())
+
+	if !assert.NoError(t, os.MkdirAll(filepath.Dir(executable), 0755)) || !assert.NoError(t, os.WriteFile(executable, nil, 0755)) {
+		return
+	}
+
+	// current process is alive, so its record is not pruned
+	if !assert.NoError(t, w.writeRunningSession(RunningSession{ID: "s", PID: os.Getpid(), JavaPath: executable})) {
+		return
+	}
+
+	assert.ErrorIs(t, w.UninstallJRE(component), &JREInUseError{Component: component})
+	assert.FileExists(t, executable)
+
+	if !assert.NoError(t, os.Remove(filepath.Join(w.runningSessionsPath(), "s.json"))) {
+		return
+	}
+
+	if assert.NoError(t, w.UninstallJRE(component)) {
+		installed, err := w.InstalledJREs()
+		assert.NoError(t, err)
+		assert.Empty(t, installed)
+	}
+}
+
+func TestUninstallJREInUseForeground(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+
+	const component = "java-runtime-gamma"
+	executable := w.JREExecutable(component, GetJRESelector())
+
+	if !assert.NoError(t, os.MkdirAll(filepath.Dir(executable), 0755)) || !assert.NoError(t, os.WriteFile(executable, nil, 0755)) {
+		return
+	}
+
+	s := RunningSession{ID: "s", PID: os.Getpid(), JavaPath: executable, Foreground: true}
+	if !assert.NoError(t, w.writeRunningSession(s)) {
+		return
+	}
+
+	assert.ErrorIs(t, w.UninstallJRE(component), &JREInUseError{Component: component})
+	assert.FileExists(t, executable)
+
+	// managed by the launcher that started it, so it cannot be found by commands for background sessions
+	_, err := w.FindRunningSession(s.ID)
+	assert.ErrorIs(t, err, &SessionNotFoundError{})
+
+	if assert.NoError(t, w.unregisterSession(s.ID)) {
+		assert.NoError(t, w.UninstallJRE(component))
+	}
+}
+
+func TestOpenJREWithoutManifest(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+
+	const component = "java-runtime-gamma"
+	path := w.JREPath(component, GetJRESelector())
+
+	// installed from Adoptium, which leaves no manifest
+	if !assert.NoError(t, os.MkdirAll(path, 0755)) || !assert.NoError(t, os.WriteFile(filepath.Join(path, jreVersionFile), []byte("jdk-17.0.2+8"), 0644)) {
+		return
+	}
+
+	_, err := w.OpenJRE(component)
+	assert.ErrorIs(t, err, &JREVerificationUnsupportedError{Component: component})
+}
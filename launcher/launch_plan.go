@@ -0,0 +1,112 @@
+package launcher
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LaunchPlan describes how the game is going to be launched.
+type LaunchPlan struct {
+	Wrapper          []string      // Command the Java is run through, if any.
+	JavaPath         string        // Path to the Java executable.
+	Argv             []string      // Arguments passed to Java.
+	Env              []string      // Environment variables in form of key=value set in addition to the inherited environment.
+	WorkingDirectory string        // Directory the game is started in, which is the game directory.
+	ClassPath        []string      // Paths to the libraries and version jar on the class path.
+	NativesDirectory string        // Directory where natives are extracted to.
+	ProfileID        string        // Identifier of the profile being launched.
+	VersionID        string        // Identifier of the version being launched.
+	Memory           *MemoryPolicy // Memory policy the JVM arguments are based on, nil if the user has set them.
+
+	version       Version             // Version to extract natives from.
+	assetIndex    *AssetIndex         // Asset index to virtualize, if nil, assets don't need virtualization.
+	assetsMapping AssetsMappingMethod // Method used to virtualize the assets.
+	assetsPath    string              // Path where assets are virtualized.
+	secrets       []string            // Values to hide when the plan is displayed, like the access token.
+}
+
+// Command returns the name of the executable to run and its arguments, taking the wrapper into account.
+func (p *LaunchPlan) Command() (string, []string) {
+	if len(p.Wrapper) == 0 {
+		return p.JavaPath, p.Argv
+	}
+
+	var args []string
+	args = append(args, p.Wrapper[1:]...)
+	args = append(args, p.JavaPath)
+	args = append(args, p.Argv...)
+
+	return p.Wrapper[0], args
+}
+
+const redactedValue = "***"
+
+// Redacted returns a copy of the plan where secrets like the access token are replaced, making it safe to display.
+func (p *LaunchPlan) Redacted() *LaunchPlan {
+	r := *p
+
+	redact := func(values []string) []string {
+		c := make([]string, len(values))
+
+		for i, v := range values {
+			for _, s := range p.secrets {
+				if s != "" {
+					v = strings.ReplaceAll(v, s, redactedValue)
+				}
+			}
+
+			c[i] = v
+		}
+
+		return c
+	}
+
+	r.Argv = redact(p.Argv)
+	r.Env = redact(p.Env)
+	r.secrets = nil
+
+	return &r
+}
+
+// shellQuote quotes the value for use in POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// WriteScript writes POSIX shell script that starts the game as described by the plan. The script does not prepare the
+// launch, so the plan must be prepared beforehand and the natives directory must not be cleaned.
+func (p *LaunchPlan) WriteScript(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("#!/bin/sh\n")
+	_, _ = fmt.Fprintf(&b, "# %s\n", p.VersionID)
+	if p.ProfileID != "" {
+		_, _ = fmt.Fprintf(&b, "# profile: %s\n", p.ProfileID)
+	}
+	b.WriteString("\n")
+
+	_, _ = fmt.Fprintf(&b, "cd %s || exit 1\n\n", shellQuote(p.WorkingDirectory))
+
+	for _, e := range p.Env {
+		k, v, _ := strings.Cut(e, "=")
+		_, _ = fmt.Fprintf(&b, "export %s=%s\n", k, shellQuote(v))
+	}
+
+	if len(p.Env) != 0 {
+		b.WriteString("\n")
+	}
+
+	name, args := p.Command()
+
+	b.WriteString("exec " + shellQuote(name))
+
+	for _, a := range args {
+		b.WriteString(" \\\n  " + shellQuote(a))
+	}
+
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,64 @@
+package launcher
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLaunchPlanRedacted(t *testing.T) {
+	p := &LaunchPlan{
+		Argv:    []string{"--accessToken", "secret", "--session=token:secret"},
+		secrets: []string{"secret"},
+	}
+
+	r := p.Redacted()
+
+	assert.Equal(t, []string{"--accessToken", "***", "--session=token:***"}, r.Argv)
+	assert.Equal(t, "secret", p.Argv[1], "original plan must be left intact")
+}
+
+func TestLaunchPlanCommand(t *testing.T) {
+	p := &LaunchPlan{
+		JavaPath: "java",
+		Argv:     []string{"-version"},
+	}
+
+	name, args := p.Command()
+	assert.Equal(t, "java", name)
+	assert.Equal(t, []string{"-version"}, args)
+
+	p.Wrapper = []string{"strace", "-f"}
+
+	name, args = p.Command()
+	assert.Equal(t, "strace", name)
+	assert.Equal(t, []string{"-f", "java", "-version"}, args)
+}
+
+func TestLaunchPlanWriteScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX shell is not available")
+	}
+
+	p := &LaunchPlan{
+		Wrapper:          []string{"env"},
+		JavaPath:         "printf",
+		Argv:             []string{`%s|%s|%s\n`, "it's", "$HOME", "a b"},
+		Env:              []string{"MARCT_TEST=1"},
+		WorkingDirectory: t.TempDir(),
+		VersionID:        "1.18.2",
+	}
+
+	var script bytes.Buffer
+	if !assert.NoError(t, p.WriteScript(&script)) {
+		return
+	}
+
+	out, err := exec.Command("sh", "-c", script.String()).Output()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "it's|$HOME|a b\n", string(out))
+	}
+}
@@ -3,18 +3,18 @@ package launcher
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
-	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/brawaru/marct/launcher/accounts"
 	"github.com/brawaru/marct/utils"
+	"github.com/brawaru/marct/utils/osfile"
 	"github.com/brawaru/marct/utils/slices"
 	"github.com/brawaru/marct/validfile"
 	"github.com/google/shlex"
@@ -36,17 +36,31 @@ func standardJVMArguments() (args []Argument) {
 }
 
 type LaunchOptions struct {
-	Background    bool                   // Whether to launch the game in background with attacking it a child.
-	JavaPath      string                 // Path to javaw executable.
-	JavaArgs      *string                // User JVM arguments.
-	Resolution    *Resolution            // Custom resolution.
-	Authorization accounts.Authorization // Authorization.
-	GameDirectory string                 // Directory where game stores its files like resource packs.
+	Background        bool                   // Whether to launch the game detached in background, with output written to session log.
+	JavaPath          string                 // Path to javaw executable.
+	JavaArgs          *string                // User JVM arguments, if nil, heap size and GC are chosen by the memory policy.
+	MemoryHint        MemoryHint             // How demanding the game is, used by the memory policy.
+	Resolution        *Resolution            // Custom resolution.
+	Authorization     accounts.Authorization // Authorization.
+	GameDirectory     string                 // Directory where game stores its files like resource packs.
+	ProfileID         string                 // Identifier of the launched profile, used to store session records.
+	LogSinks          []LogSink              // Sinks receiving the game output, if empty, the output is printed to stdout.
+	QuickPlay         *QuickPlay             // Target to join directly on launch, if any.
+	Wrapper           string                 // Command line of the wrapper the game is run through, e.g. "gamemoderun".
+	Env               map[string]string      // Additional environment variables for the game process.
+	Force             bool                   // Whether to launch even if the game directory is used by another session.
+	IgnoreJavaVersion bool                   // Whether to launch with custom Java even if it is older than the version requires.
 }
 
 type LaunchResult struct {
 	Command          *exec.Cmd
+	Supervisor       *Supervisor
+	Session          *RunningSession // Record of the session, only set for the game launched in background.
 	NativesDirectory string
+	GameDirectory    string
+
+	instance  *Instance
+	sessionID string // Identifier of the record of the session launched in foreground.
 }
 
 type CleanError struct {
@@ -54,20 +68,38 @@ type CleanError struct {
 }
 
 func (e *CleanError) Error() string {
-	s := "failed to clean temporary directories"
+	s := "failed to clean up after the session"
 	for _, i := range e.Suppressed {
 		s += "\n  suppressed: " + i.Error()
 	}
 	return s
 }
 
+// Clean releases resources held by the launcher for the session once the game has exited. Resources of the session
+// running in background are released once its record is pruned, so nothing is done for it.
 func (r *LaunchResult) Clean() error {
+	if r.Session != nil {
+		return nil
+	}
+
 	var s []error
 
-	if err := os.RemoveAll(r.NativesDirectory); err != nil {
+	pid := r.Command.Process.Pid
+
+	if err := r.instance.releaseNatives(r.NativesDirectory, pid); err != nil {
 		s = append(s, err)
 	}
 
+	if err := unlockGameDirectory(r.GameDirectory, pid); err != nil {
+		s = append(s, err)
+	}
+
+	if r.sessionID != "" {
+		if err := r.instance.unregisterSession(r.sessionID); err != nil {
+			s = append(s, err)
+		}
+	}
+
 	if len(s) == 0 {
 		return nil
 	} else {
@@ -102,9 +134,9 @@ func interpretArgv(argv []string, vars map[string]string) []string {
 	return c
 }
 
-func (w *Instance) Launch(version Version, options LaunchOptions) (*LaunchResult, error) {
-	lr := &LaunchResult{}
-
+// PlanLaunch computes how the version would be launched with the options without making any changes on disk, other than
+// caching the probe of Java. The plan must be prepared using PrepareLaunch before the game can be started from it.
+func (w *Instance) PlanLaunch(version Version, options LaunchOptions) (*LaunchPlan, error) {
 	// Minecraft arguments:
 	// - ${auth_player_name}   Name of the player.
 	// - ${version_name}       Identifier of the version launched.
@@ -143,20 +175,17 @@ func (w *Instance) Launch(version Version, options LaunchOptions) (*LaunchResult
 		gameDirectory = filepath.Join(w.Path, gameDirectory)
 	}
 
-	if dirExists, err := validfile.DirExists(gameDirectory); err == nil {
-		if !dirExists {
-			if err := os.MkdirAll(gameDirectory, 0755); err != nil {
-				return nil, fmt.Errorf("cannot create game directory: %w", err)
-			}
-		}
-	} else {
-		return nil, fmt.Errorf("cannot check game directory: %w", err)
+	plan := &LaunchPlan{
+		WorkingDirectory: gameDirectory,
+		ProfileID:        options.ProfileID,
+		VersionID:        version.ID,
+		version:          version,
 	}
 
 	var virtualAssetsPath string
 	if version.AssetIndex != nil {
 		if ai, err := w.ReadAssetIndex(version.AssetIndex.ID); err != nil {
-			return lr, err
+			return nil, err
 		} else {
 			var vp string
 			mt := ai.GetMappingMethod()
@@ -168,22 +197,17 @@ func (w *Instance) Launch(version Version, options LaunchOptions) (*LaunchResult
 			}
 
 			if vp != "" {
-				if err := ai.Virtualize(w.DefaultAssetsObjectResolver(), vp, mt); err != nil {
-					return nil, fmt.Errorf("cannot virtualize assets: %w", err)
-				}
+				plan.assetIndex = ai
+				plan.assetsMapping = mt
+				plan.assetsPath = vp
 
 				virtualAssetsPath = w.AssetsVirtualPath(version.AssetIndex.ID) // MCL compat
 			}
 		}
 	}
 
-	var nativesDirectory string
-	if np, err := w.ExtractNatives(version); err == nil {
-		nativesDirectory = np
-		lr.NativesDirectory = np
-	} else {
-		return nil, fmt.Errorf("extract natives: %w", err)
-	}
+	nativesDirectory := w.NativesPath(version)
+	plan.NativesDirectory = nativesDirectory
 
 	ld := w.LibrariesPath()
 	var classPath []string
@@ -211,6 +235,7 @@ func (w *Instance) Launch(version Version, options LaunchOptions) (*LaunchResult
 		return nil, fmt.Errorf("path %q to version jar: %w", version.ID, err)
 	}
 	classPath = append(classPath, path)
+	plan.ClassPath = classPath
 
 	var resWidth string
 	var resHeight string
@@ -229,6 +254,37 @@ func (w *Instance) Launch(version Version, options LaunchOptions) (*LaunchResult
 		FeatCustomResolution: options.Resolution != nil,
 	}
 
+	quickPlayVars := map[string]string{}
+	var quickPlayArgv []string
+
+	if q := options.QuickPlay; q != nil {
+		if q.feature() == "" {
+			return nil, &QuickPlayUnsupportedError{Type: q.Type}
+		}
+
+		if supportsQuickPlay(version) {
+			featSet[FeatQuickPlaySupport] = true
+			featSet[q.feature()] = true
+
+			quickPlayVars["quickPlayPath"] = quickPlayLogPath(gameDirectory, time.Now())
+			switch q.Type {
+			case QuickPlaySingleplayer:
+				quickPlayVars["quickPlaySingleplayer"] = q.Target
+			case QuickPlayMultiplayer:
+				quickPlayVars["quickPlayMultiplayer"] = q.Target
+			case QuickPlayRealms:
+				quickPlayVars["quickPlayRealms"] = q.Target
+			}
+		} else {
+			a, err := legacyQuickPlayArgv(*q)
+			if err != nil {
+				return nil, err
+			}
+
+			quickPlayArgv = a
+		}
+	}
+
 	var jvmArgv []string
 
 	{
@@ -272,6 +328,8 @@ func (w *Instance) Launch(version Version, options LaunchOptions) (*LaunchResult
 		}
 	}
 
+	minecraftArgv = append(minecraftArgv, quickPlayArgv...)
+
 	argvVars := map[string]string{
 		"auth_player_name":  options.Authorization.UserName,
 		"version_name":      version.ID,
@@ -294,49 +352,58 @@ func (w *Instance) Launch(version Version, options LaunchOptions) (*LaunchResult
 		"height":            resHeight,
 	}
 
+	for k, v := range quickPlayVars {
+		argvVars[k] = v
+	}
+
 	var loggingArgv []string
-	var loggingProcessor io.Writer
 
 	if version.Logging != nil {
 		c, ok := version.Logging["client"]
-		if ok {
+		if ok && c.Type == "log4j2-xml" {
 			loggingArgv = interpretArgv([]string{c.Argument}, map[string]string{
 				"path": w.LogConfigPath(c),
 			})
-
-			switch c.Type {
-			case "log4j2-xml":
-				loggingProcessor = &Log4JWriter{
-					Consumer: func(event Log4JEvent) {
-						fmt.Printf("[%s] [%s] [%s] %s\n", event.Timestamp.Format(time.RFC3339), event.Level, event.Thread, event.Message.Content)
-					},
-				}
-			}
 		}
 	}
 
-	if loggingProcessor == nil {
-		loggingProcessor = os.Stdout
+	javawPath := options.JavaPath
+
+	if javawPath == "" {
+		component := JavaComponent(version)
+		javawPath = w.JREExecutable(component, GetJRESelector())
+
+		if _, err := os.Stat(javawPath); err != nil {
+			return nil, &JavaUnavailableError{
+				System:  GetJRESelector(),
+				Version: component,
+				Errno:   ErrExecutableMissing,
+			}
+		}
 	}
 
 	var userArgv []string
 
 	{
-		var s string
-
 		if options.JavaArgs == nil {
-			s = DefaultJVMArgs
+			// arguments must be understood by the runtime running the game, which may not be the one the version
+			// requires, G1 is used if it cannot be probed
+			java, _ := w.ProbeJava(javawPath)
+
+			// without memory information the policy falls back to the target heap size
+			memory, _ := ReadSystemMemory()
+
+			plan.Memory = ChooseMemoryPolicy(RequiredJavaMajorVersion(version), java, options.MemoryHint, memory)
+			userArgv = plan.Memory.Args()
 		} else {
-			s = *options.JavaArgs
-		}
+			a, err := shlex.Split(*options.JavaArgs)
 
-		a, err := shlex.Split(s)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse JVM arguments %q: %w", *options.JavaArgs, err)
+			}
 
-		if err != nil {
-			return nil, fmt.Errorf("cannot parse JVM arguments %q: %w", *options.JavaArgs, err)
+			userArgv = a
 		}
-
-		userArgv = a
 	}
 
 	// [JVM arguments] [user JVM arguments] [config argument] [mainClass] [minecraft arguments]
@@ -348,39 +415,267 @@ func (w *Instance) Launch(version Version, options LaunchOptions) (*LaunchResult
 	argv = append(argv, version.MainClass)
 	argv = append(argv, interpretArgv(minecraftArgv, argvVars)...)
 
-	var javawPath string
-
-	if options.JavaPath == "" {
-		var j string
-		if version.JavaVersion == nil {
-			j = "jre-legacy"
-		} else {
-			j = version.JavaVersion.Component
+	if options.Wrapper != "" {
+		a, err := shlex.Split(options.Wrapper)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse wrapper command %q: %w", options.Wrapper, err)
 		}
-		javawPath = filepath.FromSlash(fmt.Sprintf("%s/%s/bin/java", w.JREPath(j, GetJRESelector()), j))
-		if runtime.GOOS == "windows" {
-			javawPath += ".exe"
+
+		plan.Wrapper = a
+	}
+
+	for k, v := range options.Env {
+		plan.Env = append(plan.Env, k+"="+v)
+	}
+
+	sort.Strings(plan.Env)
+
+	plan.JavaPath = javawPath
+	plan.Argv = argv
+	plan.secrets = []string{options.Authorization.AccessToken}
+
+	return plan, nil
+}
+
+// PrepareLaunch makes changes on disk required to start the game from the plan: creates the game directory, virtualizes
+// the assets and extracts the natives.
+func (w *Instance) PrepareLaunch(plan *LaunchPlan) error {
+	if dirExists, err := validfile.DirExists(plan.WorkingDirectory); err == nil {
+		if !dirExists {
+			if err := os.MkdirAll(plan.WorkingDirectory, 0755); err != nil {
+				return fmt.Errorf("cannot create game directory: %w", err)
+			}
 		}
 	} else {
-		javawPath = options.JavaPath
+		return fmt.Errorf("cannot check game directory: %w", err)
+	}
+
+	if plan.assetIndex != nil {
+		if err := plan.assetIndex.Virtualize(w.DefaultAssetsObjectResolver(), plan.assetsPath, plan.assetsMapping); err != nil {
+			return fmt.Errorf("cannot virtualize assets: %w", err)
+		}
+	}
+
+	if err := w.extractNatives(plan.version, plan.NativesDirectory); err != nil {
+		return fmt.Errorf("extract natives: %w", err)
+	}
+
+	return nil
+}
+
+// abortLaunch releases resources held by the launcher for the session that has failed to start.
+func (w *Instance) abortLaunch(plan *LaunchPlan) {
+	_ = w.releaseNatives(plan.NativesDirectory, os.Getpid())
+	_ = unlockGameDirectory(plan.WorkingDirectory, os.Getpid())
+}
+
+// killLaunch kills the game that has been started, but cannot be supervised, and releases resources held for it.
+func (w *Instance) killLaunch(plan *LaunchPlan, cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+
+	pid := cmd.Process.Pid
+	_ = w.releaseNatives(plan.NativesDirectory, pid)
+	_ = unlockGameDirectory(plan.WorkingDirectory, pid)
+
+	w.abortLaunch(plan)
+}
+
+// handOverLaunch makes the started game process the holder of the resources acquired by the launcher for the session,
+// so they are held for as long as the game runs, even if the launcher exits.
+func (w *Instance) handOverLaunch(plan *LaunchPlan, s *Supervisor) error {
+	pid := s.Command.Process.Pid
+
+	if err := w.transferNatives(plan.NativesDirectory, os.Getpid(), pid); err != nil {
+		return fmt.Errorf("transfer natives: %w", err)
+	}
+
+	if err := transferGameDirectory(plan.WorkingDirectory, s.ID(), pid); err != nil {
+		return fmt.Errorf("transfer game directory: %w", err)
+	}
+
+	return nil
+}
+
+// Launch plans, prepares and starts the game.
+//
+// If custom Java is set, it is probed first and launch fails with JavaVersionMismatchError if it's older than the version
+// requires, unless LaunchOptions.IgnoreJavaVersion is set.
+//
+// For the life of the session, the game directory is locked. If it's already used by another session, launch fails with
+// GameDirectoryBusyError, unless LaunchOptions.Force is set.
+func (w *Instance) Launch(version Version, options LaunchOptions) (*LaunchResult, error) {
+	plan, err := w.PlanLaunch(version, options)
+	if err != nil {
+		return nil, err
 	}
 
 	if os.Getenv("MARCT_MODE") == "debug" {
-		argvStr := strings.Join(argv, "\n ")
-		argvStr = strings.Replace(argvStr, options.Authorization.AccessToken, "***", -1)
-		fmt.Printf("java: %q\nargv:\n %s\n", javawPath, argvStr)
+		r := plan.Redacted()
+		fmt.Printf("java: %q\nargv:\n %s\n", r.JavaPath, strings.Join(r.Argv, "\n "))
+	}
+
+	if options.JavaPath != "" && !options.IgnoreJavaVersion {
+		if _, err := w.CheckJava(options.JavaPath, version); err != nil {
+			return nil, err
+		}
+	}
+
+	// resources are held by the launcher until the game is started
+	if err := lockGameDirectory(plan.WorkingDirectory, plan.ProfileID, options.Force); err != nil {
+		return nil, err
+	}
+
+	if err := w.acquireNatives(plan.NativesDirectory, os.Getpid()); err != nil {
+		w.abortLaunch(plan)
+		return nil, fmt.Errorf("acquire natives: %w", err)
+	}
+
+	if err := w.PrepareLaunch(plan); err != nil {
+		w.abortLaunch(plan)
+		return nil, err
+	}
+
+	name, args := plan.Command()
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), plan.Env...)
+	cmd.Dir = plan.WorkingDirectory
+
+	if options.Background {
+		return w.launchDetached(plan, cmd, options)
+	}
+
+	var sink LogSink
+	if len(options.LogSinks) == 0 {
+		sink = NewTerminalSink(os.Stdout, false)
+	} else {
+		sink = &multiSink{sinks: options.LogSinks, failed: map[LogSink]bool{}}
+	}
+
+	// versions without logging configuration print plain text, which Log4JWriter passes through line by line
+	loggingProcessor := &Log4JWriter{
+		Consumer: func(event Log4JEvent) {
+			_ = sink.Write(event.Record())
+		},
+		LineConsumer: func(line string) {
+			_ = sink.Write(LogRecord{
+				Time:    time.Now(),
+				Message: line,
+				Raw:     true,
+			})
+		},
 	}
 
-	cmd := exec.Command(javawPath, argv...)
-	cmd.Env = os.Environ()
-	cmd.Dir = gameDirectory
 	cmd.Stdout = loggingProcessor
 	cmd.Stderr = os.Stderr
-	if err := cmd.Start(); err != nil {
+
+	s := w.newSupervisor(cmd, plan.ProfileID, plan.VersionID, plan.WorkingDirectory)
+	if err := s.Start(); err != nil {
+		w.abortLaunch(plan)
+		return nil, err
+	}
+
+	if err := w.handOverLaunch(plan, s); err != nil {
+		w.killLaunch(plan, cmd)
 		return nil, err
 	}
 
-	lr.Command = cmd
+	// registered too, so that the runtime and other resources in use can be told by other launcher processes
+	rs := w.runningSession(plan, s, options)
+	rs.Foreground = true
+
+	if err := w.writeRunningSession(rs); err != nil {
+		w.killLaunch(plan, cmd)
+		return nil, fmt.Errorf("register session: %w", err)
+	}
+
+	return &LaunchResult{
+		Command:          cmd,
+		Supervisor:       s,
+		NativesDirectory: plan.NativesDirectory,
+		GameDirectory:    plan.WorkingDirectory,
+		instance:         w,
+		sessionID:        rs.ID,
+	}, nil
+}
+
+// launchDetached starts the command in background with its output redirected to the session log file, and registers the
+// session as running.
+func (w *Instance) launchDetached(plan *LaunchPlan, cmd *exec.Cmd, options LaunchOptions) (*LaunchResult, error) {
+	logsDir := w.SessionLogsPath()
+
+	logFile, err := osfile.New(sessionLogName(logsDir, time.Now()))
+	if err != nil {
+		w.abortLaunch(plan)
+		return nil, fmt.Errorf("create session log: %w", err)
+	}
+
+	defer utils.DClose(logFile) // the child has its own handle
 
-	return lr, nil
+	// logs of the sessions still running in background are followed by the logs command
+	inUse, err := w.RunningSessionLogs()
+	if err == nil {
+		err = rotateSessionLogs(logsDir, SessionLogsKeep, inUse)
+	}
+
+	if err != nil {
+		warnRotateFailed(err)
+	}
+
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+
+	s := w.newSupervisor(cmd, plan.ProfileID, plan.VersionID, plan.WorkingDirectory)
+	s.detached = true
+
+	if err := s.Start(); err != nil {
+		w.abortLaunch(plan)
+		return nil, err
+	}
+
+	if err := w.handOverLaunch(plan, s); err != nil {
+		w.killLaunch(plan, cmd)
+		return nil, err
+	}
+
+	rs := w.runningSession(plan, s, options)
+	rs.LogFile = logFile.Name()
+
+	if err := w.writeRunningSession(rs); err != nil {
+		w.killLaunch(plan, cmd)
+		return nil, fmt.Errorf("register session: %w", err)
+	}
+
+	if err := cmd.Process.Release(); err != nil {
+		return nil, fmt.Errorf("release process: %w", err)
+	}
+
+	return &LaunchResult{
+		Command:          cmd,
+		Supervisor:       s,
+		Session:          &rs,
+		NativesDirectory: plan.NativesDirectory,
+		GameDirectory:    plan.WorkingDirectory,
+		instance:         w,
+	}, nil
+}
+
+// runningSession creates the record of the session started by the supervisor.
+func (w *Instance) runningSession(plan *LaunchPlan, s *Supervisor, options LaunchOptions) RunningSession {
+	// without start time, the session is only checked by PID
+	startTime, _ := processStartTime(s.Command.Process.Pid)
+
+	return RunningSession{
+		ID:               s.ID(),
+		ProfileID:        plan.ProfileID,
+		VersionID:        plan.VersionID,
+		Account:          options.Authorization.UserName,
+		PID:              s.Command.Process.Pid,
+		ProcessStartTime: startTime,
+		StartedAt:        s.StartedAt(),
+		NativesDirectory: plan.NativesDirectory,
+		GameDirectory:    plan.WorkingDirectory,
+		JavaPath:         plan.JavaPath,
+	}
 }
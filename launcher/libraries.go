@@ -10,7 +10,6 @@ import (
 	"github.com/brawaru/marct/launcher/download"
 	"github.com/brawaru/marct/locales"
 	"github.com/brawaru/marct/maven"
-	"github.com/brawaru/marct/utils/terrgroup"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 )
 
@@ -28,7 +27,10 @@ func hasEmptyPath(u url.URL) bool {
 	return u.Path == "" || u.Path == "/"
 }
 
-func (w *Instance) DownloadLibrary(library *Library) error {
+// libraryDownloads returns downloads of the library artifact and its natives matching the current system.
+func (w *Instance) libraryDownloads(library *Library) ([]*download.Download, error) {
+	var downloads []*download.Download
+
 	if library.URL != nil || library.Downloads == nil {
 		dlPath := w.LibraryPath(library.Coordinates)
 
@@ -42,81 +44,96 @@ func (w *Instance) DownloadLibrary(library *Library) error {
 		src, urlErr := url.Parse(mavenServer)
 
 		if urlErr != nil {
-			return urlErr
+			return nil, urlErr
 		}
 
 		if hasEmptyPath(*src) {
 			src.Path = library.Coordinates.Path('/')
 		}
 
-		if err := download.From(src, dlPath, download.WithRemoteSHA1(), download.WithRemoteMD5()); err != nil {
-			return fmt.Errorf("download %s: %s", dlPath, err)
+		d, err := download.New(src, dlPath, download.WithRemoteSHA1(), download.WithRemoteMD5())
+		if err != nil {
+			return nil, fmt.Errorf("download %s: %w", dlPath, err)
 		}
+
+		downloads = append(downloads, d)
 	} else {
 		artifact := library.Downloads.Artifact
 
 		if artifact != nil {
 			dest := filepath.Join(w.LibrariesPath(), filepath.FromSlash(artifact.Path))
 
-			if err := download.FromURL(artifact.URL, dest, download.WithSHA1(artifact.SHA1)); err != nil {
-				return fmt.Errorf("download %s: %s", artifact.URL, err)
+			d, err := download.NewURL(artifact.URL, dest, download.WithSHA1(artifact.SHA1), download.WithSize(artifact.Size))
+			if err != nil {
+				return nil, fmt.Errorf("download %s: %w", artifact.URL, err)
 			}
+
+			downloads = append(downloads, d)
 		}
 	}
 
 	if natives := library.GetMatchingNatives(); natives != nil {
 		dest := filepath.Join(w.LibrariesPath(), filepath.FromSlash(natives.Path))
 
-		if err := download.FromURL(natives.URL, dest, download.WithSHA1(natives.SHA1)); err != nil {
-			return fmt.Errorf("download native %s: %s", natives.URL, err)
+		d, err := download.NewURL(natives.URL, dest, download.WithSHA1(natives.SHA1), download.WithSize(natives.Size))
+		if err != nil {
+			return nil, fmt.Errorf("download native %s: %w", natives.URL, err)
 		}
+
+		downloads = append(downloads, d)
 	}
 
-	return nil
+	return downloads, nil
 }
 
-func (w *Instance) DownloadLibraries(libraries []Library) error {
-	g, _ := terrgroup.New(8)
+func (w *Instance) DownloadLibrary(library *Library) error {
+	downloads, err := w.libraryDownloads(library)
+	if err != nil {
+		return err
+	}
+
+	return download.Run(downloads)
+}
+
+// librariesDownloads returns downloads of all libraries matching the current system.
+func (w *Instance) librariesDownloads(libraries []Library) ([]*download.Download, error) {
+	var downloads []*download.Download
 
 	for _, l := range libraries {
 		library := l
 
-		g.Go(func() error {
-			if library.Rules != nil && !library.Rules.Matches() {
-				if globstate.VerboseLogs {
-					println(locales.TranslateUsing(&i18n.LocalizeConfig{
-						TemplateData: map[string]string{
-							"Library": library.Coordinates.String(),
-						},
-						DefaultMessage: &i18n.Message{
-							ID:    "log.verbose.library-skipped-over-rules",
-							Other: "skipping library {{ .Library }} since it does not match rules",
-						},
-					}))
-				}
-
-				return nil
+		if library.Rules != nil && !library.Rules.Matches() {
+			if globstate.VerboseLogs {
+				println(locales.TranslateUsing(&i18n.LocalizeConfig{
+					TemplateData: map[string]string{
+						"Library": library.Coordinates.String(),
+					},
+					DefaultMessage: &i18n.Message{
+						ID:    "log.verbose.library-skipped-over-rules",
+						Other: "skipping library {{ .Library }} since it does not match rules",
+					},
+				}))
 			}
 
-			if dlErr := w.DownloadLibrary(&library); dlErr == nil {
-				if globstate.VerboseLogs {
-					println(locales.TranslateUsing(&i18n.LocalizeConfig{
-						TemplateData: map[string]string{
-							"Library": library.Coordinates.String(),
-						},
-						DefaultMessage: &i18n.Message{
-							ID:    "log.verbose.downloaded-library",
-							Other: "downloaded library {{ .Library }}",
-						},
-					}))
-				}
-			} else {
-				return fmt.Errorf("download libraries: %w", dlErr)
-			}
+			continue
+		}
+
+		d, err := w.libraryDownloads(&library)
+		if err != nil {
+			return nil, fmt.Errorf("download libraries: %w", err)
+		}
 
-			return nil
-		})
+		downloads = append(downloads, d...)
+	}
+
+	return downloads, nil
+}
+
+func (w *Instance) DownloadLibraries(libraries []Library) error {
+	downloads, err := w.librariesDownloads(libraries)
+	if err != nil {
+		return err
 	}
 
-	return g.Wait()
+	return download.Run(downloads)
 }
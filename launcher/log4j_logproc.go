@@ -1,22 +1,205 @@
 package launcher
 
 import (
+	"bytes"
 	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"sync"
 )
 
 type Log4JEventConsumer func(event Log4JEvent)
 
+// Log4JLineConsumer is called for every line of output that is not a Log4J event, such as stack traces printed by JVM
+// before the logging is configured.
+type Log4JLineConsumer func(line string)
+
+var log4jEventTag = []byte("<log4j:Event")
+
+// Log4JWriter implements io.Writer interface where written data is decoded as a stream of Log4JEvent elements. Events
+// may be split across any number of writes, they are buffered until complete. Any text found between the events is
+// passed to LineConsumer line by line.
 type Log4JWriter struct {
-	Consumer Log4JEventConsumer
+	Consumer     Log4JEventConsumer // Consumer of the decoded events.
+	LineConsumer Log4JLineConsumer  // Consumer of the non-XML lines, if nil, such lines are dropped.
+
+	m    sync.Mutex
+	buf  []byte
+	scan eventScan
 }
 
-// Log4JWriter implements io.Writer interface where each write is parsed as XML of Log4JEvent and transformed using the provided Formatter.
-func (w *Log4JWriter) Write(data []byte) (n int, err error) {
-	var r Log4JEvent
-	err = xml.Unmarshal(data, &r)
-	if err != nil {
+func (w *Log4JWriter) emitLine(line []byte) {
+	line = bytes.TrimRight(line, "\r\n")
+
+	if len(bytes.TrimSpace(line)) == 0 || w.LineConsumer == nil {
 		return
 	}
-	w.Consumer(r)
+
+	w.LineConsumer(string(line))
+}
+
+// isIncomplete reports whether the decoding error was caused by the input ending prematurely.
+func isIncomplete(err error) bool {
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var syntaxErr *xml.SyntaxError
+	return errors.As(err, &syntaxErr) && strings.HasPrefix(syntaxErr.Msg, "unexpected EOF")
+}
+
+var (
+	cdataStart = []byte("<![CDATA[")
+	cdataEnd   = []byte("]]>")
+)
+
+// eventScan is the progress of scanning the event at the start of the buffer. It is kept between writes, so that the
+// event written in many parts is not tokenized from the beginning on every write.
+type eventScan struct {
+	offset int // Offset after the last token known to be complete.
+	depth  int // Number of elements open at the offset.
+	cdata  int // Offset up to which the end of the CDATA section following the offset has been searched for.
+}
+
+// length returns the length of the event element at the start of data, or zero if data does not contain a complete
+// element yet. Data may only grow between the calls.
+func (s *eventScan) length(data []byte) (int, error) {
+	if i := bytes.IndexByte(data[s.offset:], '<'); i != -1 && bytes.HasPrefix(data[s.offset+i:], cdataStart) {
+		// CDATA section is a single token, there is nothing to decode until it ends
+		from := s.offset + i + len(cdataStart)
+		if s.cdata > from {
+			from = s.cdata
+		}
+
+		if bytes.Index(data[from:], cdataEnd) == -1 {
+			s.cdata = len(data) - len(cdataEnd) + 1
+			return 0, nil
+		}
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(data[s.offset:]))
+
+	base := s.offset
+
+	for {
+		start := base + int(d.InputOffset())
+
+		t, err := d.RawToken()
+		if err != nil {
+			if isIncomplete(err) {
+				return 0, nil
+			}
+
+			return 0, err
+		}
+
+		end := base + int(d.InputOffset())
+
+		switch t.(type) {
+		case xml.StartElement:
+			s.depth++
+
+			if data[end-2] == '/' {
+				continue // self-closing, its end element follows without reading any further
+			}
+		case xml.EndElement:
+			s.depth--
+
+			if s.depth == 0 {
+				return end, nil
+			}
+		case xml.CharData:
+			if !bytes.HasPrefix(data[start:], cdataStart) {
+				continue // text may continue in the next write
+			}
+		}
+
+		s.offset = end
+	}
+}
+
+// consume discards n bytes at the start of the buffer, along with the progress of scanning them.
+func (w *Log4JWriter) consume(n int) {
+	w.buf = w.buf[n:]
+	w.scan = eventScan{}
+}
+
+// process consumes as many complete events and lines from the buffer as possible.
+func (w *Log4JWriter) process() {
+	for len(w.buf) > 0 {
+		start := len(w.buf) - len(bytes.TrimLeft(w.buf, " \t\r\n"))
+		rest := w.buf[start:]
+
+		if len(rest) == 0 {
+			return // only whitespace, wait for more data
+		}
+
+		if bytes.HasPrefix(log4jEventTag, rest) && len(rest) < len(log4jEventTag) {
+			return // possibly a beginning of the event, wait for more data
+		}
+
+		if bytes.HasPrefix(rest, log4jEventTag) {
+			n, err := w.scan.length(rest)
+
+			if err == nil {
+				if n == 0 {
+					return // event is incomplete, wait for more data
+				}
+
+				var event Log4JEvent
+				if unmarshalErr := xml.Unmarshal(rest[:n], &event); unmarshalErr == nil {
+					if w.Consumer != nil {
+						w.Consumer(event)
+					}
+
+					w.consume(start + n)
+					continue
+				}
+			}
+
+			// malformed event, fall back to treating it as a plain text
+		}
+
+		end := bytes.IndexByte(w.buf, '\n')
+
+		if next := bytes.Index(w.buf[start+1:], log4jEventTag); next != -1 && (end == -1 || start+1+next < end) {
+			// event begins on the same line
+			end = start + next
+		}
+
+		if end == -1 {
+			return // line is incomplete, wait for more data
+		}
+
+		w.emitLine(w.buf[:end+1])
+		w.consume(end + 1)
+	}
+}
+
+func (w *Log4JWriter) Write(data []byte) (n int, err error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.buf = append(w.buf, data...)
+	w.process()
+
 	return len(data), nil
 }
+
+// Flush passes any buffered incomplete data to the LineConsumer. It must be called once the stream has ended.
+func (w *Log4JWriter) Flush() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.process()
+
+	for _, line := range bytes.Split(w.buf, []byte("\n")) {
+		w.emitLine(line)
+	}
+
+	w.buf = nil
+	w.scan = eventScan{}
+
+	return nil
+}
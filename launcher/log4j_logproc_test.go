@@ -0,0 +1,140 @@
+package launcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const log4jStream = `Picked up _JAVA_OPTIONS: -Dawt.useSystemAAFontSettings=on
+<log4j:Event logger="MyLogger" timestamp="1648475574576" level="INFO" thread="main">
+  <log4j:Message><![CDATA[Setting user: Player]]></log4j:Message>
+</log4j:Event>
+
+<log4j:Event logger="MyLogger" timestamp="1648475574577" level="ERROR" thread="Render thread">
+  <log4j:Message><![CDATA[Something went <wrong>]]></log4j:Message>
+  <log4j:Throwable><![CDATA[java.lang.IllegalStateException: oops
+	at net.minecraft.client.Main.<init>(Main.java:1)
+]]></log4j:Throwable>
+</log4j:Event>
+
+Exception in thread "main" java.lang.NoClassDefFoundError: a/b
+	at java.lang.Thread.<init>(Thread.java:1)
+`
+
+func decodeLog4JStream(t *testing.T, chunkSize int) {
+	var events []Log4JEvent
+	var lines []string
+
+	w := &Log4JWriter{
+		Consumer: func(event Log4JEvent) {
+			events = append(events, event)
+		},
+		LineConsumer: func(line string) {
+			lines = append(lines, line)
+		},
+	}
+
+	data := []byte(log4jStream)
+
+	for len(data) > 0 {
+		n := chunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+
+		written, err := w.Write(data[:n])
+		if !assert.NoError(t, err) || !assert.Equal(t, n, written) {
+			return
+		}
+
+		data = data[n:]
+	}
+
+	if !assert.NoError(t, w.Flush()) {
+		return
+	}
+
+	if !assert.Len(t, events, 2, "chunk size %d", chunkSize) {
+		return
+	}
+
+	assert.Equal(t, "Setting user: Player", events[0].Message.Content)
+	assert.Nil(t, events[0].Throwable)
+	assert.Equal(t, "Render thread", events[1].Thread)
+	assert.Equal(t, "Something went <wrong>", events[1].Message.Content)
+
+	if assert.NotNil(t, events[1].Throwable) {
+		assert.Contains(t, events[1].Throwable.Content, "java.lang.IllegalStateException: oops")
+	}
+
+	assert.Equal(t, []string{
+		"Picked up _JAVA_OPTIONS: -Dawt.useSystemAAFontSettings=on",
+		`Exception in thread "main" java.lang.NoClassDefFoundError: a/b`,
+		"\tat java.lang.Thread.<init>(Thread.java:1)",
+	}, lines, "chunk size %d", chunkSize)
+}
+
+func TestLog4JWriterStreaming(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 7, 16, 64, len(log4jStream)} {
+		decodeLog4JStream(t, size)
+	}
+}
+
+func TestLog4JWriterUnterminatedLine(t *testing.T) {
+	var lines []string
+
+	w := &Log4JWriter{
+		LineConsumer: func(line string) {
+			lines = append(lines, line)
+		},
+	}
+
+	_, _ = w.Write([]byte("Error: Could not find or load main class"))
+
+	assert.Empty(t, lines, "incomplete lines must be buffered")
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, []string{"Error: Could not find or load main class"}, lines)
+}
+
+func TestEventScanResumes(t *testing.T) {
+	const event = `<log4j:Event logger="a"><log4j:Message><![CDATA[<init> in a long message]]></log4j:Message><log4j:Properties/></log4j:Event>`
+
+	var s eventScan
+
+	for i := 1; i < len(event); i++ {
+		n, err := s.length([]byte(event[:i]))
+		if !assert.NoError(t, err) || !assert.Zero(t, n, "event is incomplete at %d", i) {
+			return
+		}
+	}
+
+	// scanning continues after the last complete token
+	assert.Equal(t, strings.Index(event, "</log4j:Event>"), s.offset)
+	assert.Equal(t, 1, s.depth)
+
+	n, err := s.length([]byte(event))
+	if assert.NoError(t, err) {
+		assert.Equal(t, len(event), n)
+	}
+}
+
+func TestEventScanCDATA(t *testing.T) {
+	data := []byte(`<log4j:Event logger="a"><log4j:Message><![CDATA[first line`)
+
+	var s eventScan
+
+	for _, more := range []string{"", "\nsecond line"} {
+		data = append(data, more...)
+
+		n, err := s.length(data)
+		if !assert.NoError(t, err) || !assert.Zero(t, n) {
+			return
+		}
+	}
+
+	// CDATA section that has not ended is not decoded again, only searched for its end
+	assert.Equal(t, len(`<log4j:Event logger="a"><log4j:Message>`), s.offset)
+	assert.Equal(t, len(data)-len(cdataEnd)+1, s.cdata)
+}
@@ -0,0 +1,329 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brawaru/marct/locales"
+	"github.com/brawaru/marct/utils/osfile"
+	"github.com/brawaru/marct/utils/slices"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// LogLevel is a severity of the log record.
+type LogLevel int
+
+const (
+	LevelUnknown LogLevel = iota // Level is unknown, such records are never filtered out.
+	LevelTrace
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+var logLevelNames = map[LogLevel]string{
+	LevelTrace: "TRACE",
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+	LevelFatal: "FATAL",
+}
+
+func (l LogLevel) String() string {
+	return logLevelNames[l]
+}
+
+// ParseLogLevel parses the name of the level as used by Log4J (e.g. "WARN"), case-insensitively.
+func ParseLogLevel(s string) (LogLevel, error) {
+	s = strings.ToUpper(s)
+
+	for l, n := range logLevelNames {
+		if n == s {
+			return l, nil
+		}
+	}
+
+	return LevelUnknown, fmt.Errorf("unknown log level %q", s)
+}
+
+// LogRecord is a single record of the game output, either decoded from the Log4J event or passed through as plain text.
+type LogRecord struct {
+	Time      time.Time `json:"time"`                // Time when the record was made.
+	Logger    string    `json:"logger,omitempty"`    // Name of the logger.
+	Level     LogLevel  `json:"-"`                   // Severity of the record.
+	Thread    string    `json:"thread,omitempty"`    // Name of the thread that made the record.
+	Message   string    `json:"message"`             // Message of the record.
+	Throwable string    `json:"throwable,omitempty"` // Stack trace of the attached throwable, if any.
+	Raw       bool      `json:"raw,omitempty"`       // Whether the record is a plain text line and not a structured event.
+}
+
+func (r LogRecord) MarshalJSON() ([]byte, error) {
+	type record LogRecord
+	return json.Marshal(struct {
+		record
+		Level string `json:"level,omitempty"`
+	}{record(r), r.Level.String()})
+}
+
+// Record converts the event to the log record.
+func (e Log4JEvent) Record() LogRecord {
+	l, _ := ParseLogLevel(e.Level)
+
+	r := LogRecord{
+		Time:    e.Timestamp.Time,
+		Logger:  e.Logger,
+		Level:   l,
+		Thread:  e.Thread,
+		Message: e.Message.Content,
+	}
+
+	if e.Throwable != nil {
+		r.Throwable = e.Throwable.Content
+	}
+
+	return r
+}
+
+// LogSink receives records of the game output.
+type LogSink interface {
+	// Write writes the record to the sink.
+	Write(r LogRecord) error
+	// Close flushes and closes the sink.
+	Close() error
+}
+
+// formatRecord formats the record as a human-readable text without trailing line break.
+func formatRecord(r LogRecord) string {
+	if r.Raw {
+		return r.Message
+	}
+
+	s := fmt.Sprintf("[%s] [%s] [%s] %s", r.Time.Format(time.RFC3339), r.Level, r.Thread, r.Message)
+
+	if r.Throwable != "" {
+		s += "\n" + strings.TrimRight(r.Throwable, "\r\n")
+	}
+
+	return s
+}
+
+// TerminalSink writes human-readable records to the terminal, optionally coloring them by their level.
+type TerminalSink struct {
+	w     io.Writer
+	color bool
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiGray   = "\x1b[90m"
+	ansiYellow = "\x1b[33m"
+	ansiRed    = "\x1b[31m"
+)
+
+func NewTerminalSink(w io.Writer, color bool) *TerminalSink {
+	return &TerminalSink{w, color}
+}
+
+func (s *TerminalSink) Write(r LogRecord) error {
+	line := formatRecord(r)
+
+	if s.color {
+		var c string
+		switch r.Level {
+		case LevelTrace, LevelDebug:
+			c = ansiGray
+		case LevelWarn:
+			c = ansiYellow
+		case LevelError, LevelFatal:
+			c = ansiRed
+		}
+
+		if c != "" {
+			line = c + line + ansiReset
+		}
+	}
+
+	_, err := io.WriteString(s.w, line+"\n")
+	return err
+}
+
+func (s *TerminalSink) Close() error {
+	return nil
+}
+
+// JSONLinesSink writes every record as a JSON object on a separate line.
+type JSONLinesSink struct {
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLinesSink creates a sink writing to w. If w is an io.Closer, it will be closed together with the sink.
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w, json.NewEncoder(w)}
+}
+
+func (s *JSONLinesSink) Write(r LogRecord) error {
+	return s.enc.Encode(r)
+}
+
+func (s *JSONLinesSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok && s.w != os.Stdout && s.w != os.Stderr {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// FileSink writes human-readable records to a file.
+type FileSink struct {
+	f *os.File
+}
+
+// Name returns the path to the file the sink writes to.
+func (s *FileSink) Name() string {
+	return s.f.Name()
+}
+
+func (s *FileSink) Write(r LogRecord) error {
+	_, err := s.f.WriteString(formatRecord(r) + "\n")
+	return err
+}
+
+func (s *FileSink) Close() error {
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", s.f.Name(), err)
+	}
+
+	return s.f.Close()
+}
+
+// NewSessionFileSink creates a file sink writing to a new marct-session-{time}.log file in the directory. At most keep
+// session log files are retained in the directory, older ones are deleted, except for the ones in use, like the ones
+// returned by Instance.RunningSessionLogs. Failure to delete them is only warned about.
+func NewSessionFileSink(dir string, keep int, inUse ...string) (*FileSink, error) {
+	name := sessionLogName(dir, time.Now())
+
+	f, err := osfile.New(name)
+	if err != nil {
+		return nil, fmt.Errorf("create %s: %w", name, err)
+	}
+
+	if err := rotateSessionLogs(dir, keep, inUse); err != nil {
+		warnRotateFailed(err)
+	}
+
+	return &FileSink{f}, nil
+}
+
+// warnRotateFailed warns that old session logs could not be deleted, which only leaves them taking space.
+func warnRotateFailed(err error) {
+	println(locales.TranslateUsing(&i18n.LocalizeConfig{
+		TemplateData: map[string]string{
+			"Error": err.Error(),
+		},
+		DefaultMessage: &i18n.Message{
+			ID:    "warning.session-logs-rotate-failed",
+			Other: "failed to delete old session logs: {{ .Error }}",
+		},
+	}))
+}
+
+// sessionLogName returns path to the session log file created at the time.
+func sessionLogName(dir string, t time.Time) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%s.log", sessionLogPrefix, t.Format("2006-01-02_15-04-05.000")))
+}
+
+// rotateSessionLogs deletes all session log files in the directory, except the keep most recent ones and the ones in
+// use, which are still written to by running sessions.
+func rotateSessionLogs(dir string, keep int, inUse []string) error {
+	matches, err := filepath.Glob(filepath.Join(dir, sessionLogPrefix+"*.log"))
+	if err != nil {
+		return err
+	}
+
+	if len(matches) <= keep {
+		return nil
+	}
+
+	// names contain the time, so they are sorted chronologically
+	sort.Strings(matches)
+
+	for _, m := range matches[:len(matches)-keep] {
+		if slices.Includes(inUse, m) {
+			continue
+		}
+
+		if err := os.Remove(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type levelFilterSink struct {
+	min  LogLevel
+	sink LogSink
+}
+
+// FilterLevel wraps the sink to only receive records with level of at least min. Records with unknown level, like plain
+// text lines, are always passed.
+func FilterLevel(min LogLevel, sink LogSink) LogSink {
+	return &levelFilterSink{min, sink}
+}
+
+func (s *levelFilterSink) Write(r LogRecord) error {
+	if r.Level != LevelUnknown && r.Level < s.min {
+		return nil
+	}
+
+	return s.sink.Write(r)
+}
+
+func (s *levelFilterSink) Close() error {
+	return s.sink.Close()
+}
+
+// multiSink dispatches records to multiple sinks. Sink errors are reported only once to avoid flooding the output.
+type multiSink struct {
+	m      sync.Mutex
+	sinks  []LogSink
+	failed map[LogSink]bool
+}
+
+func (s *multiSink) Write(r LogRecord) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, sink := range s.sinks {
+		if err := sink.Write(r); err != nil && !s.failed[sink] {
+			s.failed[sink] = true
+
+			println(locales.TranslateUsing(&i18n.LocalizeConfig{
+				TemplateData: map[string]string{
+					"Error": err.Error(),
+				},
+				DefaultMessage: &i18n.Message{
+					ID:    "warning.log-sink-write-failed",
+					Other: "failed to write game output to the log sink: {{ .Error }}",
+				},
+			}))
+		}
+	}
+
+	return nil
+}
+
+func (s *multiSink) Close() error {
+	return nil // sinks are owned by the caller
+}
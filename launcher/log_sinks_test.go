@@ -0,0 +1,109 @@
+package launcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterLevel(t *testing.T) {
+	var buf bytes.Buffer
+
+	s := FilterLevel(LevelWarn, NewTerminalSink(&buf, false))
+
+	assert.NoError(t, s.Write(LogRecord{Level: LevelInfo, Message: "info"}))
+	assert.NoError(t, s.Write(LogRecord{Level: LevelError, Message: "error", Raw: true}))
+	assert.NoError(t, s.Write(LogRecord{Message: "plain", Raw: true}))
+
+	assert.Equal(t, "error\nplain\n", buf.String())
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	s := NewJSONLinesSink(&buf)
+
+	assert.NoError(t, s.Write(LogRecord{
+		Time:    time.Unix(1648475574, 0).UTC(),
+		Logger:  "MyLogger",
+		Level:   LevelWarn,
+		Thread:  "main",
+		Message: "hello",
+	}))
+
+	var decoded map[string]any
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded)) {
+		assert.Equal(t, "WARN", decoded["level"])
+		assert.Equal(t, "hello", decoded["message"])
+		assert.Equal(t, "2022-03-28T13:52:54Z", decoded["time"])
+	}
+}
+
+func TestRotateSessionLogs(t *testing.T) {
+	dir := t.TempDir()
+
+	names := []string{
+		sessionLogPrefix + "2022-01-01_00-00-00.000.log",
+		sessionLogPrefix + "2022-01-02_00-00-00.000.log",
+		sessionLogPrefix + "2022-01-03_00-00-00.000.log",
+		"latest.log",
+	}
+
+	for _, n := range names {
+		if !assert.NoError(t, os.WriteFile(filepath.Join(dir, n), nil, 0644)) {
+			return
+		}
+	}
+
+	if !assert.NoError(t, rotateSessionLogs(dir, 2, nil)) {
+		return
+	}
+
+	assert.NoFileExists(t, filepath.Join(dir, names[0]))
+	assert.FileExists(t, filepath.Join(dir, names[1]))
+	assert.FileExists(t, filepath.Join(dir, names[2]))
+	assert.FileExists(t, filepath.Join(dir, "latest.log"))
+}
+
+func TestNewSessionFileSinkRotateFailed(t *testing.T) {
+	dir := t.TempDir()
+
+	// directory that is not empty cannot be removed
+	old := filepath.Join(dir, sessionLogPrefix+"2022-01-01_00-00-00.000.log")
+	if !assert.NoError(t, os.MkdirAll(filepath.Join(old, "child"), 0755)) {
+		return
+	}
+
+	s, err := NewSessionFileSink(dir, 0)
+	if assert.NoError(t, err, "failed rotation must not prevent logging") {
+		assert.NoError(t, s.Close())
+	}
+}
+
+func TestRotateSessionLogsInUse(t *testing.T) {
+	dir := t.TempDir()
+
+	var names []string
+	for _, n := range []string{"2022-01-01_00-00-00.000", "2022-01-02_00-00-00.000", "2022-01-03_00-00-00.000"} {
+		name := filepath.Join(dir, sessionLogPrefix+n+".log")
+		if !assert.NoError(t, os.WriteFile(name, nil, 0644)) {
+			return
+		}
+
+		names = append(names, name)
+	}
+
+	// the oldest session is still running in background
+	if !assert.NoError(t, rotateSessionLogs(dir, 1, []string{names[0]})) {
+		return
+	}
+
+	assert.FileExists(t, names[0])
+	assert.NoFileExists(t, names[1])
+	assert.FileExists(t, names[2])
+}
@@ -10,6 +10,11 @@ type Log4JMessage struct {
 	Content string   `xml:",cdata"`
 }
 
+type Log4JThrowable struct {
+	XMLName xml.Name `xml:"Throwable"`
+	Content string   `xml:",cdata"`
+}
+
 type Log4JEvent struct {
 	XMLName   xml.Name                `xml:"Event"`
 	Logger    string                  `xml:"logger,attr"`
@@ -17,4 +22,5 @@ type Log4JEvent struct {
 	Level     string                  `xml:"level,attr"`
 	Thread    string                  `xml:"thread,attr"`
 	Message   Log4JMessage            `xml:"Message"`
+	Throwable *Log4JThrowable         `xml:"Throwable,omitempty"`
 }
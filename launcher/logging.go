@@ -11,11 +11,26 @@ func (w *Instance) LogConfigPath(logConfig LoggingConfiguration) string {
 	return filepath.Join(w.Path, filepath.FromSlash(logConfigsPath), logConfig.File.ID)
 }
 
-func (w *Instance) DownloadLogConfig(logConfig LoggingConfiguration) error {
+// logConfigDownload returns download of the log configuration file.
+func (w *Instance) logConfigDownload(logConfig LoggingConfiguration) (*download.Download, error) {
 	dest := w.LogConfigPath(logConfig)
 
-	if err := download.FromURL(logConfig.File.URL, dest, download.WithSHA1(logConfig.File.SHA1)); err != nil {
-		return fmt.Errorf("download %s to %q: %s", logConfig.File.URL, dest, err)
+	d, err := download.NewURL(logConfig.File.URL, dest, download.WithSHA1(logConfig.File.SHA1), download.WithSize(logConfig.File.Size))
+	if err != nil {
+		return nil, fmt.Errorf("download %s to %q: %w", logConfig.File.URL, dest, err)
+	}
+
+	return d, nil
+}
+
+func (w *Instance) DownloadLogConfig(logConfig LoggingConfiguration) error {
+	d, err := w.logConfigDownload(logConfig)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Download(); err != nil {
+		return fmt.Errorf("download %s to %q: %w", logConfig.File.URL, d.Destination, err)
 	}
 
 	return nil
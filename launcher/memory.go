@@ -0,0 +1,271 @@
+package launcher
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/brawaru/marct/utils/slices"
+)
+
+// ErrMemoryInfoUnavailable is returned when the amount of memory cannot be determined on the current system.
+var ErrMemoryInfoUnavailable = errors.New("memory information is not available on this system")
+
+// SystemMemory describes the memory of the system.
+type SystemMemory struct {
+	Total     uint64 // Total amount of memory in bytes.
+	Available uint64 // Amount of memory in bytes available for new processes without swapping.
+}
+
+// parseMeminfo parses the memory information in format of /proc/meminfo.
+func parseMeminfo(r io.Reader) (*SystemMemory, error) {
+	values := map[string]uint64{}
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		k, v, ok := strings.Cut(s.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(v)
+		if len(fields) == 0 {
+			continue
+		}
+
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", k, err)
+		}
+
+		if len(fields) > 1 && fields[1] == "kB" {
+			n *= 1024
+		}
+
+		values[k] = n
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	total, ok := values["MemTotal"]
+	if !ok {
+		return nil, errors.New("MemTotal is missing")
+	}
+
+	available, ok := values["MemAvailable"]
+	if !ok {
+		// kernels older than 3.14 don't report available memory
+		available = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+
+	return &SystemMemory{Total: total, Available: available}, nil
+}
+
+// MemoryHint describes how demanding the game launched by the profile is.
+type MemoryHint string
+
+const (
+	MemoryHintVanilla     MemoryHint = "vanilla"      // Game without mods.
+	MemoryHintLightModded MemoryHint = "light-modded" // Game with a few mods, like optimisation mods.
+	MemoryHintHeavyModded MemoryHint = "heavy-modded" // Game with a large modpack.
+)
+
+// MemoryHints lists all known memory hints.
+var MemoryHints = []MemoryHint{MemoryHintVanilla, MemoryHintLightModded, MemoryHintHeavyModded}
+
+// ParseMemoryHint parses the memory hint, empty string is treated as vanilla.
+func ParseMemoryHint(s string) (MemoryHint, error) {
+	if s == "" {
+		return MemoryHintVanilla, nil
+	}
+
+	for _, h := range MemoryHints {
+		if string(h) == s {
+			return h, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown memory hint %q", s)
+}
+
+const (
+	mib = 1024 * 1024
+
+	minHeapMiB       = 1024 // Heap below this size is not enough for any version.
+	systemReserveMiB = 1024 // Memory left for the system and other applications.
+)
+
+// targetHeapMiB returns the maximum heap size the game is expected to need.
+func targetHeapMiB(javaMajor int, hint MemoryHint) uint64 {
+	var target uint64
+
+	switch hint {
+	case MemoryHintHeavyModded:
+		target = 8192
+	case MemoryHintLightModded:
+		target = 4096
+	default:
+		target = 2048
+	}
+
+	// versions running on Java 17+ (1.18+) have higher world height and need more memory for chunks
+	if javaMajor >= 17 && hint != MemoryHintHeavyModded {
+		target += 1024
+	}
+
+	return target
+}
+
+// GC is a garbage collector of the JVM.
+type GC string
+
+const (
+	GCG1         GC = "G1"
+	GCShenandoah GC = "Shenandoah"
+	GCZ          GC = "ZGC"
+)
+
+// gcArgs returns arguments enabling and tuning the garbage collector.
+func gcArgs(gc GC, javaMajor int) []string {
+	switch gc {
+	case GCZ:
+		args := []string{"-XX:+UseZGC"}
+		if javaMajor >= 21 {
+			args = append(args, "-XX:+ZGenerational")
+		}
+		return args
+	case GCShenandoah:
+		return []string{"-XX:+UseShenandoahGC"}
+	default:
+		return []string{
+			"-XX:+UnlockExperimentalVMOptions",
+			"-XX:+UseG1GC",
+			"-XX:G1NewSizePercent=20",
+			"-XX:G1ReservePercent=20",
+			"-XX:MaxGCPauseMillis=50",
+			"-XX:G1HeapRegionSize=32M",
+		}
+	}
+}
+
+// shenandoahVendors are vendors known to include Shenandoah in their builds. Oracle does not build it in.
+var shenandoahVendors = []string{"Adoptium", "Amazon", "Azul", "BellSoft", "Microsoft", "Red Hat", "SAP"}
+
+// zgcArchs are architectures ZGC is available on.
+var zgcArchs = []string{"amd64", "x86_64", "aarch64"}
+
+// supportsGC checks whether the runtime is known to have the garbage collector built in.
+func supportsGC(java *JavaInstallation, gc GC) bool {
+	switch gc {
+	case GCG1:
+		return true
+	case GCZ:
+		return slices.Includes(zgcArchs, java.Arch)
+	case GCShenandoah:
+		for _, v := range shenandoahVendors {
+			if strings.Contains(java.Vendor, v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// chooseGC picks the garbage collector suited to the runtime and heap size. G1 is picked if the runtime is unknown or
+// not known to support the better suited collector.
+func chooseGC(java *JavaInstallation, xmxMiB uint64) GC {
+	if java == nil {
+		return GCG1
+	}
+
+	var gc GC
+
+	switch {
+	case java.MajorVersion >= 21:
+		// generational ZGC keeps pauses short without the throughput loss of non-generational one
+		gc = GCZ
+	case java.MajorVersion >= 17 && xmxMiB >= 4096:
+		// Shenandoah pauses don't grow with heap, which matters for large modded heaps
+		gc = GCShenandoah
+	default:
+		gc = GCG1
+	}
+
+	if !supportsGC(java, gc) {
+		return GCG1
+	}
+
+	return gc
+}
+
+// MemoryPolicy is the decision on how much memory to give the game and which garbage collector to use.
+type MemoryPolicy struct {
+	Hint   MemoryHint    // Hint the decision is based on.
+	Memory *SystemMemory // Memory of the system, nil if unknown.
+	XmsMiB uint64        // Initial heap size in MiB.
+	XmxMiB uint64        // Maximum heap size in MiB.
+	GC     GC            // Garbage collector.
+	Capped bool          // Whether the heap has been reduced from the target due to the lack of memory.
+
+	java *JavaInstallation // Runtime running the game, nil if unknown.
+}
+
+// Args returns JVM arguments implementing the policy.
+func (p *MemoryPolicy) Args() []string {
+	args := []string{
+		fmt.Sprintf("-Xms%dM", p.XmsMiB),
+		fmt.Sprintf("-Xmx%dM", p.XmxMiB),
+	}
+
+	javaMajor := 0
+	if p.java != nil {
+		javaMajor = p.java.MajorVersion
+	}
+
+	return append(args, gcArgs(p.GC, javaMajor)...)
+}
+
+// ChooseMemoryPolicy decides the heap size and garbage collector for the game requiring Java of the major version. The
+// garbage collector is chosen among the ones the probed runtime running the game supports, or G1 if java is nil. If
+// memory of the system is known, the heap is capped to leave enough memory for the system.
+func ChooseMemoryPolicy(javaMajor int, java *JavaInstallation, hint MemoryHint, memory *SystemMemory) *MemoryPolicy {
+	p := &MemoryPolicy{
+		Hint:   hint,
+		Memory: memory,
+		XmxMiB: targetHeapMiB(javaMajor, hint),
+		java:   java,
+	}
+
+	if memory != nil {
+		// never take more than a half of the total memory, or what's available without swapping
+		limit := memory.Total / mib / 2
+
+		if available := memory.Available / mib; available > systemReserveMiB && available-systemReserveMiB < limit {
+			limit = available - systemReserveMiB
+		}
+
+		if limit < minHeapMiB {
+			limit = minHeapMiB
+		}
+
+		if p.XmxMiB > limit {
+			p.XmxMiB = limit
+			p.Capped = true
+		}
+	}
+
+	p.XmsMiB = p.XmxMiB / 2
+	if p.XmsMiB < minHeapMiB/2 {
+		p.XmsMiB = minHeapMiB / 2
+	}
+
+	p.GC = chooseGC(java, p.XmxMiB)
+
+	return p
+}
@@ -0,0 +1,22 @@
+//go:build linux
+
+package launcher
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/brawaru/marct/utils"
+)
+
+// ReadSystemMemory reads the memory information of the system from /proc/meminfo.
+func ReadSystemMemory() (*SystemMemory, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return nil, fmt.Errorf("open /proc/meminfo: %w", err)
+	}
+
+	defer utils.DClose(f)
+
+	return parseMeminfo(f)
+}
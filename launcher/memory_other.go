@@ -0,0 +1,8 @@
+//go:build !linux
+
+package launcher
+
+// ReadSystemMemory is not implemented on this system and always returns ErrMemoryInfoUnavailable.
+func ReadSystemMemory() (*SystemMemory, error) {
+	return nil, ErrMemoryInfoUnavailable
+}
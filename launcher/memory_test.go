@@ -0,0 +1,72 @@
+package launcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	m, err := parseMeminfo(strings.NewReader(`MemTotal:       16314916 kB
+MemFree:         1232380 kB
+MemAvailable:    9468732 kB
+Buffers:          412508 kB
+HugePages_Total:       0
+`))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(16314916*1024), m.Total)
+		assert.Equal(t, uint64(9468732*1024), m.Available)
+	}
+
+	_, err = parseMeminfo(strings.NewReader("MemFree: 1232380 kB\n"))
+	assert.Error(t, err)
+}
+
+func TestChooseMemoryPolicy(t *testing.T) {
+	gib := func(n uint64) uint64 { return n * 1024 * mib }
+
+	temurin17 := &JavaInstallation{Vendor: "Eclipse Adoptium", MajorVersion: 17, Arch: "amd64"}
+	microsoft21 := &JavaInstallation{Vendor: "Microsoft", MajorVersion: 21, Arch: "amd64"}
+
+	p := ChooseMemoryPolicy(8, nil, MemoryHintVanilla, nil)
+	assert.Equal(t, uint64(2048), p.XmxMiB)
+	assert.Equal(t, uint64(1024), p.XmsMiB)
+	assert.Equal(t, GCG1, p.GC)
+	assert.False(t, p.Capped)
+
+	p = ChooseMemoryPolicy(17, temurin17, MemoryHintHeavyModded, &SystemMemory{Total: gib(32), Available: gib(24)})
+	assert.Equal(t, uint64(8192), p.XmxMiB)
+	assert.Equal(t, GCShenandoah, p.GC)
+	assert.Equal(t, []string{"-Xms4096M", "-Xmx8192M", "-XX:+UseShenandoahGC"}, p.Args())
+
+	p = ChooseMemoryPolicy(21, microsoft21, MemoryHintHeavyModded, &SystemMemory{Total: gib(8), Available: gib(3)})
+	assert.Equal(t, uint64(2048), p.XmxMiB, "heap must leave memory for the system")
+	assert.True(t, p.Capped)
+	assert.Equal(t, GCZ, p.GC)
+	assert.Contains(t, p.Args(), "-XX:+ZGenerational")
+
+	p = ChooseMemoryPolicy(17, nil, MemoryHintVanilla, &SystemMemory{Total: gib(2), Available: gib(1) / 2})
+	assert.Equal(t, uint64(minHeapMiB), p.XmxMiB, "heap must not go below minimum")
+}
+
+func TestChooseMemoryPolicyRuntime(t *testing.T) {
+	memory := &SystemMemory{Total: 32 * 1024 * mib, Available: 24 * 1024 * mib}
+
+	// Oracle does not build Shenandoah in
+	oracle17 := &JavaInstallation{Vendor: "Oracle Corporation", MajorVersion: 17, Arch: "amd64"}
+	assert.Equal(t, GCG1, ChooseMemoryPolicy(17, oracle17, MemoryHintHeavyModded, memory).GC)
+
+	// runtime older than the version requires must not get arguments it does not know
+	temurin17 := &JavaInstallation{Vendor: "Eclipse Adoptium", MajorVersion: 17, Arch: "amd64"}
+	p := ChooseMemoryPolicy(21, temurin17, MemoryHintVanilla, memory)
+	assert.Equal(t, GCG1, p.GC)
+	assert.NotContains(t, p.Args(), "-XX:+ZGenerational")
+
+	// unknown runtime
+	assert.Equal(t, GCG1, ChooseMemoryPolicy(21, nil, MemoryHintHeavyModded, memory).GC)
+
+	arm21 := &JavaInstallation{Vendor: "Oracle Corporation", MajorVersion: 21, Arch: "arm"}
+	assert.Equal(t, GCG1, ChooseMemoryPolicy(21, arm21, MemoryHintHeavyModded, memory).GC)
+}
@@ -0,0 +1,50 @@
+package launcher
+
+import (
+	"strings"
+
+	"github.com/brawaru/marct/network"
+)
+
+// mirrorPrefix resolves the prefix of the mirror rule, which is either a URL, a host or a name of the official server.
+func mirrorPrefix(prefix string) string {
+	if strings.Contains(prefix, "://") {
+		return prefix
+	}
+
+	if endpoint, ok := mirrorEndpoints[prefix]; ok {
+		return endpoint
+	}
+
+	return "https://" + strings.TrimSuffix(prefix, "/") + "/"
+}
+
+// MirrorRules converts mirror settings to the rules used to rewrite the URLs of requests. Rules without prefix are
+// ignored, as they would otherwise apply to all requests.
+func (s NetworkSettings) MirrorRules() []network.MirrorRule {
+	var rules []network.MirrorRule
+
+	for _, m := range s.Mirrors {
+		if m.Prefix == "" {
+			continue
+		}
+
+		rule := network.MirrorRule{
+			Prefix:     mirrorPrefix(m.Prefix),
+			SkipOrigin: m.SkipOrigin,
+		}
+
+		for _, u := range m.URLs {
+			// the rest of the URL follows the slash of the prefix
+			if strings.HasSuffix(rule.Prefix, "/") && !strings.HasSuffix(u, "/") {
+				u += "/"
+			}
+
+			rule.Mirrors = append(rule.Mirrors, u)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
@@ -0,0 +1,11 @@
+package launcher
+
+// mirrorEndpoints are the official servers, keyed by names that can be used as the prefix of the mirror rule.
+var mirrorEndpoints = map[string]string{
+	"piston-meta":  "https://piston-meta.mojang.com/",
+	"piston-data":  "https://piston-data.mojang.com/",
+	"launchermeta": "https://launchermeta.mojang.com/",
+	"launcher":     "https://launcher.mojang.com/",
+	"resources":    resourcesURL + "/",
+	"libraries":    mojangMavenServer + "/",
+}
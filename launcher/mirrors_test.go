@@ -0,0 +1,25 @@
+package launcher
+
+import (
+	"testing"
+
+	"github.com/brawaru/marct/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorRules(t *testing.T) {
+	s := NetworkSettings{
+		Mirrors: []MirrorSettings{
+			{Prefix: "libraries", URLs: []string{"https://mirror.example/maven"}},
+			{Prefix: "resources.download.minecraft.net", URLs: []string{"https://mirror.example/assets/"}, SkipOrigin: true},
+			{Prefix: "https://piston-meta.mojang.com/mc/", URLs: []string{"https://mirror.example/mc/"}},
+			{URLs: []string{"https://mirror.example/"}},
+		},
+	}
+
+	assert.Equal(t, []network.MirrorRule{
+		{Prefix: "https://libraries.minecraft.net/", Mirrors: []string{"https://mirror.example/maven/"}},
+		{Prefix: "https://resources.download.minecraft.net/", Mirrors: []string{"https://mirror.example/assets/"}, SkipOrigin: true},
+		{Prefix: "https://piston-meta.mojang.com/mc/", Mirrors: []string{"https://mirror.example/mc/"}},
+	}, s.MirrorRules())
+}
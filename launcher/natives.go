@@ -1,30 +1,119 @@
 package launcher
 
 import (
-	"archive/zip"
 	"crypto/sha1"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/brawaru/marct/utils"
-	"github.com/brawaru/marct/utils/unzipper"
+	"github.com/brawaru/marct/utils/archive"
+	"github.com/brawaru/marct/utils/slices"
 	"github.com/brawaru/marct/validfile"
 )
 
+func (w *Instance) nativesRootPath() string {
+	return filepath.Join(w.Path, filepath.FromSlash(nativesPath))
+}
+
+// nativesHash returns the hash identifying the set of natives of the version matching the current system. Versions
+// sharing the same natives get the same hash, so they can share the extracted natives.
+func nativesHash(v Version) string {
+	var entries []string
+
+	for _, l := range v.Libraries {
+		n := l.GetMatchingNatives()
+		if n == nil {
+			continue
+		}
+
+		var exclude []string
+		if l.Extract != nil {
+			exclude = slices.Copy(l.Extract.Exclude)
+			sort.Strings(exclude)
+		}
+
+		entries = append(entries, strings.Join([]string{n.Path, n.SHA1, strings.Join(exclude, ",")}, "\x00"))
+	}
+
+	sort.Strings(entries)
+
+	h := sha1.New()
+	for _, e := range entries {
+		_, _ = io.WriteString(h, e+"\n")
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NativesPath returns path to the directory where natives of the version are extracted.
+func (w *Instance) NativesPath(v Version) string {
+	return filepath.Join(w.nativesRootPath(), nativesHash(v))
+}
+
+// ExtractNatives extracts natives of the version, unless they have already been extracted, and returns path to the
+// directory containing them.
 func (w *Instance) ExtractNatives(v Version) (string, error) {
-	np := filepath.Join(w.Path, "bin", utils.NewUUID())
+	np := w.NativesPath(v)
+	return np, w.extractNatives(v, np)
+}
+
+// extractNatives extracts natives into the directory, unless it already contains them. Natives are extracted into a
+// temporary directory first, which is then moved in place, so the directory never contains partially extracted natives.
+func (w *Instance) extractNatives(v Version, np string) error {
+	if complete, err := validfile.FileExists(filepath.Join(np, nativesCompleteMarker)); err != nil {
+		return fmt.Errorf("check natives: %w", err)
+	} else if complete {
+		return nil
+	}
+
+	tmp := np + nativesTempInfix + utils.NewUUID()
+
+	if err := os.MkdirAll(tmp, 0777); err != nil {
+		return fmt.Errorf("cannot create bin directory: %w", err)
+	}
 
-	if err := os.MkdirAll(np, 0777); err != nil {
-		return "", fmt.Errorf("cannot create bin directory: %w", err)
+	if err := w.unzipNatives(v, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
 	}
 
+	if err := os.WriteFile(filepath.Join(tmp, nativesCompleteMarker), nil, 0644); err != nil {
+		_ = os.RemoveAll(tmp)
+		return fmt.Errorf("mark natives complete: %w", err)
+	}
+
+	err := os.Rename(tmp, np)
+	if err != nil {
+		// concurrent launch could have extracted the same natives first
+		if complete, _ := validfile.FileExists(filepath.Join(np, nativesCompleteMarker)); complete {
+			_ = os.RemoveAll(tmp)
+			return nil
+		}
+
+		// otherwise, it's an incomplete directory left by an older version of marct
+		if removeErr := os.RemoveAll(np); removeErr == nil {
+			err = os.Rename(tmp, np)
+		}
+	}
+
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+		return fmt.Errorf("move natives in place: %w", err)
+	}
+
+	return nil
+}
+
+func (w *Instance) unzipNatives(v Version, np string) error {
 	lp := w.LibrariesPath()
 
-	nativeValidator := func(e *unzipper.Unzipper, name string, dst string, f *os.File) error {
+	nativeValidator := func(e *archive.Extractor, name string, dst string, f *os.File) error {
 		p := name + ".sha1"
 
 		if !e.Entries.HasKey(p) {
@@ -34,7 +123,7 @@ func (w *Instance) ExtractNatives(v Version) (string, error) {
 		ce := e.Entries.Get(p)
 		rc, err := ce.Open()
 		if err != nil {
-			return &unzipper.AbortErr{
+			return &archive.AbortErr{
 				Err: fmt.Errorf("cannot open %q: %w", p, err),
 			}
 		}
@@ -44,7 +133,7 @@ func (w *Instance) ExtractNatives(v Version) (string, error) {
 		{
 			buf := new(strings.Builder)
 			if _, err := io.Copy(buf, rc); err != nil {
-				return &unzipper.AbortErr{
+				return &archive.AbortErr{
 					Err: fmt.Errorf("cannot read %q: %w", p, err),
 				}
 			}
@@ -57,7 +146,7 @@ func (w *Instance) ExtractNatives(v Version) (string, error) {
 
 		var v *validfile.ValidateError
 		if errors.As(err, &v) && !v.Mismatch() {
-			return &unzipper.AbortErr{
+			return &archive.AbortErr{
 				Err: v.Err,
 			}
 		}
@@ -65,14 +154,14 @@ func (w *Instance) ExtractNatives(v Version) (string, error) {
 		return err
 	}
 
-	metaSkipper := func(o *ExtractOptions) unzipper.EntryProcessor {
-		return func(e *unzipper.Unzipper, name string, f *zip.File, dest string) error {
-			if !f.FileInfo().IsDir() {
+	metaSkipper := func(o *ExtractOptions) archive.EntryProcessor {
+		return func(e *archive.Extractor, name string, f *archive.Entry, dest string) error {
+			if f.Type != archive.TypeDir {
 				switch filepath.Ext(name) {
 				case ".sha1":
 					fallthrough
 				case ".git":
-					return unzipper.ErrSkip
+					return archive.ErrSkip
 				default:
 					return nil
 				}
@@ -82,7 +171,7 @@ func (w *Instance) ExtractNatives(v Version) (string, error) {
 				return nil
 			}
 
-			return unzipper.ErrSkip
+			return archive.ErrSkip
 		}
 	}
 
@@ -94,11 +183,11 @@ func (w *Instance) ExtractNatives(v Version) (string, error) {
 
 		ap := filepath.Join(lp, filepath.FromSlash(n.Path))
 
-		err := unzipper.Unzip(ap, np, unzipper.WithFileValidator(nativeValidator), unzipper.WithEntryProcessor(metaSkipper(l.Extract)))
+		err := archive.Extract(ap, np, archive.WithFormat(archive.FormatZip), archive.WithFileValidator(nativeValidator), archive.WithEntryProcessor(metaSkipper(l.Extract)))
 		if err != nil {
-			return np, fmt.Errorf("extract native %q: %w", l.Coordinates.String(), err)
+			return fmt.Errorf("extract native %q: %w", l.Coordinates.String(), err)
 		}
 	}
 
-	return np, nil
+	return nil
 }
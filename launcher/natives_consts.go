@@ -0,0 +1,26 @@
+package launcher
+
+const (
+	// Path where natives are extracted to.
+	//
+	// It is followed by /{hash}
+	//
+	// Where:
+	//
+	// - hash is SHA-1 hash of the set of natives extracted into the directory, see nativesHash.
+	nativesPath = "bin"
+	// Path where references to the natives held by the running sessions reside.
+	//
+	// It is followed by /{hash}/{pid}
+	//
+	// Where:
+	//
+	// - hash is the hash of the natives directory the reference holds.
+	//
+	// - pid is the process ID of the holder, references of the dead processes are stale.
+	nativesRefsPath = "marct_natives_refs"
+	// Name of the file created in the natives directory once the extraction is complete.
+	nativesCompleteMarker = ".marct-complete"
+	// Infix of the temporary directories where natives are extracted before being moved in place.
+	nativesTempInfix = ".tmp-"
+)
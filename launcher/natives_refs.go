@@ -0,0 +1,186 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brawaru/marct/utils"
+)
+
+// nativesHashPattern matches names of the natives directories keyed by hash.
+var nativesHashPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// nativesTempMaxAge is the age after which temporary natives directories are considered abandoned.
+const nativesTempMaxAge = time.Hour
+
+func (w *Instance) nativesRefsPath(hash string) string {
+	return filepath.Join(w.Path, filepath.FromSlash(nativesRefsPath), hash)
+}
+
+// acquireNatives adds a reference to the natives directory held by the process with the PID, preventing the directory
+// from being collected while the process is alive. The reference records the start time of the process, so that it is
+// not held by another process given the same PID.
+func (w *Instance) acquireNatives(np string, pid int) error {
+	dir := w.nativesRefsPath(filepath.Base(np))
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	var content []byte
+
+	// without start time, the reference is only checked by PID
+	if startTime, err := processStartTime(pid); err == nil {
+		content = []byte(strconv.FormatInt(startTime, 10))
+	}
+
+	name := filepath.Join(dir, strconv.Itoa(pid))
+	if err := os.WriteFile(name, content, 0644); err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// releaseNatives removes the reference to the natives directory held by the process with the PID.
+func (w *Instance) releaseNatives(np string, pid int) error {
+	name := filepath.Join(w.nativesRefsPath(filepath.Base(np)), strconv.Itoa(pid))
+
+	if err := os.Remove(name); err != nil && !utils.DoesNotExist(err) {
+		return fmt.Errorf("remove %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// transferNatives moves the reference to the natives directory from one process to another. The directory stays
+// referenced throughout.
+func (w *Instance) transferNatives(np string, from int, to int) error {
+	if err := w.acquireNatives(np, to); err != nil {
+		return err
+	}
+
+	return w.releaseNatives(np, from)
+}
+
+// nativesRefAlive checks whether the process holding the reference with the name is still running.
+func nativesRefAlive(name string) bool {
+	pid, err := strconv.Atoi(filepath.Base(name))
+	if err != nil {
+		return false
+	}
+
+	// references written by older versions are empty
+	var startTime int64
+	if b, err := os.ReadFile(name); err == nil && len(b) != 0 {
+		startTime, _ = strconv.ParseInt(string(b), 10, 64)
+	}
+
+	return processRunning(pid, startTime)
+}
+
+// nativesReferenced checks whether any alive process holds a reference to the natives with the hash. Stale references
+// are removed along the way.
+func (w *Instance) nativesReferenced(hash string) (bool, error) {
+	dir := w.nativesRefsPath(hash)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if utils.DoesNotExist(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("read dir %s: %w", dir, err)
+	}
+
+	referenced := false
+
+	for _, entry := range entries {
+		name := filepath.Join(dir, entry.Name())
+
+		if nativesRefAlive(name) {
+			referenced = true
+			continue
+		}
+
+		if err := os.Remove(name); err != nil {
+			return false, fmt.Errorf("remove stale reference %s: %w", name, err)
+		}
+	}
+
+	if !referenced {
+		if err := os.Remove(dir); err != nil && !utils.DoesNotExist(err) {
+			return false, fmt.Errorf("remove %s: %w", dir, err)
+		}
+	}
+
+	return referenced, nil
+}
+
+// GCNatives removes natives directories that are not used by any running session: directories left by older versions
+// of marct and abandoned incomplete extractions. If all is true, unused cached natives are removed as well, they will
+// be extracted again on the next launch.
+//
+// It returns paths of the removed directories.
+func (w *Instance) GCNatives(all bool) ([]string, error) {
+	root := w.nativesRootPath()
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if utils.DoesNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read dir %s: %w", root, err)
+	}
+
+	var removed []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		path := filepath.Join(root, name)
+
+		switch {
+		case nativesHashPattern.MatchString(name):
+			if !all {
+				continue
+			}
+
+			referenced, err := w.nativesReferenced(name)
+			if err != nil {
+				return removed, err
+			}
+
+			if referenced {
+				continue
+			}
+		case strings.Contains(name, nativesTempInfix):
+			// extraction could still be in progress
+			info, err := entry.Info()
+			if err != nil {
+				return removed, fmt.Errorf("stat %s: %w", path, err)
+			}
+
+			if time.Since(info.ModTime()) < nativesTempMaxAge {
+				continue
+			}
+		}
+
+		if err := os.RemoveAll(path); err != nil {
+			return removed, fmt.Errorf("remove %s: %w", path, err)
+		}
+
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,83 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNativesHash(t *testing.T) {
+	system := currentOS()
+
+	lib := func(path string, sha1 string) Library {
+		return Library{
+			Natives: map[string]string{system: "natives"},
+			Downloads: &LibraryDownloads{
+				Classifiers: map[string]*Artifact{
+					"natives": {Download: Download{SHA1: sha1}, Path: path},
+				},
+			},
+		}
+	}
+
+	a := Version{Libraries: []Library{lib("a.jar", "aa"), lib("b.jar", "bb")}}
+	b := Version{Libraries: []Library{lib("b.jar", "bb"), lib("a.jar", "aa")}}
+	c := Version{Libraries: []Library{lib("a.jar", "aa"), lib("b.jar", "cc")}}
+
+	assert.Equal(t, nativesHash(a), nativesHash(b), "order of libraries must not matter")
+	assert.NotEqual(t, nativesHash(a), nativesHash(c))
+	assert.Regexp(t, nativesHashPattern, nativesHash(a))
+}
+
+func TestGCNatives(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+	root := w.nativesRootPath()
+
+	cached := filepath.Join(root, nativesHash(Version{}))
+	held := filepath.Join(root, "0000000000000000000000000000000000000000")
+	legacy := filepath.Join(root, "0123456789abcdef0123456789abcdef")
+	freshTemp := filepath.Join(root, nativesHash(Version{})+nativesTempInfix+"fresh")
+	staleTemp := filepath.Join(root, nativesHash(Version{})+nativesTempInfix+"stale")
+
+	for _, d := range []string{cached, held, legacy, freshTemp, staleTemp} {
+		if !assert.NoError(t, os.MkdirAll(d, 0755)) {
+			return
+		}
+	}
+
+	old := time.Now().Add(-2 * nativesTempMaxAge)
+	if !assert.NoError(t, os.Chtimes(staleTemp, old, old)) {
+		return
+	}
+
+	if !assert.NoError(t, w.acquireNatives(held, os.Getpid())) {
+		return
+	}
+
+	// the process that has been holding the natives exited, and its PID has been given to this process since
+	if startTime, err := processStartTime(os.Getpid()); err == nil {
+		reused := filepath.Join(w.nativesRefsPath(filepath.Base(cached)), strconv.Itoa(os.Getpid()))
+
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(reused), 0755)) ||
+			!assert.NoError(t, os.WriteFile(reused, []byte(strconv.FormatInt(startTime-1, 10)), 0644)) {
+			return
+		}
+	}
+
+	removed, err := w.GCNatives(false)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []string{legacy, staleTemp}, removed)
+	}
+
+	removed, err = w.GCNatives(true)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []string{cached}, removed)
+	}
+
+	assert.DirExists(t, held)
+	assert.DirExists(t, freshTemp)
+}
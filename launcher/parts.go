@@ -0,0 +1,55 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brawaru/marct/globstate"
+	"github.com/brawaru/marct/launcher/download"
+)
+
+// sweepParts removes temporary files of the downloads that have been abandoned in the directories the launcher
+// downloads to, unless they have been swept recently. Failure to do so is not fatal, as the files are only taking space.
+func (w *Instance) sweepParts() {
+	stamp := filepath.Join(w.Path, filepath.FromSlash(partsSweptPath))
+
+	if stat, err := os.Stat(stamp); err == nil && time.Since(stat.ModTime()) < partsSweepInterval {
+		return
+	}
+
+	err := download.SweepParts(
+		w.LibrariesPath(),
+		filepath.Join(w.Path, filepath.FromSlash(assetsPath)),
+		filepath.Dir(filepath.Join(w.Path, filepath.FromSlash(versionsManifestPath))),
+		w.jreRuntimesPath(),
+		w.httpCachePath(),
+	)
+
+	if err == nil {
+		err = touchFile(stamp)
+	}
+
+	if err != nil && globstate.VerboseLogs {
+		fmt.Printf("cannot remove abandoned downloads: %s\n", err)
+	}
+}
+
+// touchFile creates the file or updates its modification time.
+func touchFile(name string) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return fmt.Errorf("mkdir %q: %w", filepath.Dir(name), err)
+	}
+
+	if err := os.WriteFile(name, nil, 0644); err != nil {
+		return fmt.Errorf("write %q: %w", name, err)
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(name, now, now); err != nil {
+		return fmt.Errorf("chtimes %q: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,11 @@
+package launcher
+
+import "time"
+
+const (
+	// Path of the file modified each time temporary files of the abandoned downloads are swept.
+	partsSweptPath = "marct_cache/parts_swept"
+	// Time after which the directories are swept for temporary files of the abandoned downloads again. Walking them
+	// takes a while, so it is not done on every download.
+	partsSweepInterval = 24 * time.Hour
+)
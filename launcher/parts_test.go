@@ -0,0 +1,42 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweepPartsThrottled(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+
+	abandon := func(name string) string {
+		p := filepath.Join(w.LibrariesPath(), name+".part")
+		if !assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755)) || !assert.NoError(t, os.WriteFile(p, nil, 0644)) {
+			t.FailNow()
+		}
+
+		old := time.Now().Add(-2 * time.Hour)
+		assert.NoError(t, os.Chtimes(p, old, old))
+
+		return p
+	}
+
+	first := abandon("a.jar")
+	w.sweepParts()
+	assert.NoFileExists(t, first)
+
+	// directories have just been swept
+	second := abandon("b.jar")
+	w.sweepParts()
+	assert.FileExists(t, second)
+
+	stamp := filepath.Join(w.Path, filepath.FromSlash(partsSweptPath))
+	old := time.Now().Add(-partsSweepInterval - time.Minute)
+	if assert.NoError(t, os.Chtimes(stamp, old, old)) {
+		w.sweepParts()
+		assert.NoFileExists(t, second)
+	}
+}
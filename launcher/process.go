@@ -0,0 +1,18 @@
+package launcher
+
+// sameProcess checks whether the process with the PID, known to be alive, is the one that has been started at
+// startTime, and not another process given the same PID. Zero start time is unknown and matches any process.
+func sameProcess(pid int, startTime int64) bool {
+	if startTime == 0 {
+		return true
+	}
+
+	// start time that cannot be read is not held against the process
+	current, err := processStartTime(pid)
+	return err != nil || current == startTime
+}
+
+// processRunning checks whether the process with the PID that has been started at startTime is still running.
+func processRunning(pid int, startTime int64) bool {
+	return processAlive(pid) && sameProcess(pid, startTime)
+}
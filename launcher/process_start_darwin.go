@@ -0,0 +1,14 @@
+package launcher
+
+import "golang.org/x/sys/unix"
+
+// processStartTime returns the time the process with the PID has been started at, in nanoseconds since the epoch. It
+// tells apart processes that have been given the same PID.
+func processStartTime(pid int) (int64, error) {
+	k, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil {
+		return 0, err
+	}
+
+	return k.Proc.P_starttime.Nano(), nil
+}
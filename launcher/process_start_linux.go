@@ -0,0 +1,28 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processStartTime returns the time the process with the PID has been started at, in clock ticks since the boot. It
+// tells apart processes that have been given the same PID.
+func processStartTime(pid int) (int64, error) {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+
+	// name of the executable is in parentheses and may contain any characters
+	s := string(b)
+	fields := strings.Fields(s[strings.LastIndexByte(s, ')')+1:])
+
+	// starttime is the 22nd field, the first two are PID and the name
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("malformed stat of process %d", pid)
+	}
+
+	return strconv.ParseInt(fields[19], 10, 64)
+}
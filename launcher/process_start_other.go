@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package launcher
+
+import "errors"
+
+// processStartTime is not implemented for this system, processes with the same PID cannot be told apart.
+func processStartTime(_ int) (int64, error) {
+	return 0, errors.New("process start time is not available on this system")
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package launcher
+
+import (
+	"errors"
+	"syscall"
+)
+
+// detachedProcAttr returns attributes starting the process in its own process group, so it is not affected by signals
+// sent to the launcher, like the terminal being closed.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+// processAlive checks whether the process with the PID exists.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminateProcess sends termination signal to the process group led by the process with the PID. If force is true, the
+// group is killed instead.
+func terminateProcess(pid int, force bool) error {
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+
+	return syscall.Kill(-pid, sig)
+}
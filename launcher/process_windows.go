@@ -0,0 +1,71 @@
+//go:build windows
+
+package launcher
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code reported for the processes that haven't exited yet.
+const stillActive = 259
+
+// detachedProcAttr returns attributes starting the process without console in its own process group, so it is not
+// affected by the console of the launcher being closed.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+	}
+}
+
+// processAlive checks whether the process with the PID exists and hasn't exited yet.
+func processAlive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+
+	defer func() {
+		_ = windows.CloseHandle(h)
+	}()
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+
+	return code == stillActive
+}
+
+// processStartTime returns the time the process with the PID has been started at, in nanoseconds since the epoch. It
+// tells apart processes that have been given the same PID.
+func processStartTime(pid int) (int64, error) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_ = windows.CloseHandle(h)
+	}()
+
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(h, &creation, &exit, &kernel, &user); err != nil {
+		return 0, err
+	}
+
+	return creation.Nanoseconds(), nil
+}
+
+// terminateProcess terminates the process with the PID. Windows has no graceful termination for processes without
+// console, so force is ignored.
+func terminateProcess(pid int, _ bool) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+
+	return p.Kill()
+}
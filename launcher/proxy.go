@@ -0,0 +1,72 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/brawaru/marct/network"
+)
+
+// ErrUnsupportedProxy is returned when the proxy URL has a scheme other than http, https or socks5.
+var ErrUnsupportedProxy = errors.New("unsupported proxy scheme")
+
+// ProxyURL parses the URL of the proxy, returning nil if the proxy is not set.
+func (s ProxySettings) ProxyURL() (*url.URL, error) {
+	if s.URL == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse %q as url: %w", s.URL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProxy, u.Scheme)
+	}
+
+	return u, nil
+}
+
+// KeyringKey returns the key under which the password of the proxy user is kept in the keyring, or empty string if the
+// proxy requires no authentication.
+func (s ProxySettings) KeyringKey() string {
+	u, err := s.ProxyURL()
+	if err != nil || u == nil || s.User == "" {
+		return ""
+	}
+
+	return "proxy:" + s.User + "@" + u.Host
+}
+
+// TransportOptions converts network settings to the options of the transport used by all requests, authenticating
+// with the proxy using the password, if the user is set.
+func (s NetworkSettings) TransportOptions(password string) (o network.TransportOptions, err error) {
+	u, err := s.Proxy.ProxyURL()
+	if err != nil {
+		return
+	}
+
+	if u != nil && s.Proxy.User != "" {
+		u.User = url.UserPassword(s.Proxy.User, password)
+	}
+
+	o.Proxy = u
+	o.NoProxy = s.Proxy.NoProxy
+
+	for _, name := range s.CAFiles {
+		b, readErr := os.ReadFile(name)
+		if readErr != nil {
+			err = fmt.Errorf("read %q: %w", name, readErr)
+			return
+		}
+
+		o.RootCAs = append(o.RootCAs, b)
+	}
+
+	return
+}
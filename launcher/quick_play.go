@@ -0,0 +1,101 @@
+package launcher
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// QuickPlayType is a kind of target the game joins directly on launch.
+type QuickPlayType string
+
+const (
+	QuickPlaySingleplayer QuickPlayType = "singleplayer" // Target is the name of the world directory.
+	QuickPlayMultiplayer  QuickPlayType = "multiplayer"  // Target is the address of the server in host[:port] format.
+	QuickPlayRealms       QuickPlayType = "realms"       // Target is the identifier of the realm.
+)
+
+// defaultServerPort is the port used when server address does not specify one.
+const defaultServerPort = 25565
+
+// QuickPlay describes the target to join directly on launch.
+type QuickPlay struct {
+	Type   QuickPlayType
+	Target string
+}
+
+// feature returns the feature enabling arguments for this type of target.
+func (q QuickPlay) feature() Feature {
+	switch q.Type {
+	case QuickPlaySingleplayer:
+		return FeatQuickPlaySingleplayer
+	case QuickPlayMultiplayer:
+		return FeatQuickPlayMultiplayer
+	case QuickPlayRealms:
+		return FeatQuickPlayRealms
+	default:
+		return ""
+	}
+}
+
+// SplitServerAddress splits the server address into host and port, using the default port if address does not have one.
+func SplitServerAddress(addr string) (host string, port int, err error) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		// most likely the port is missing, which is fine
+		if _, _, retryErr := net.SplitHostPort(net.JoinHostPort(addr, "0")); retryErr != nil {
+			return "", 0, fmt.Errorf("invalid server address %q: %w", addr, err)
+		}
+
+		return addr, defaultServerPort, nil
+	}
+
+	port, err = strconv.Atoi(p)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port %q in server address %q", p, addr)
+	}
+
+	return host, port, nil
+}
+
+// supportsQuickPlay checks whether the game arguments of the version have the Quick Play arguments.
+func supportsQuickPlay(version Version) bool {
+	if version.Arguments == nil {
+		return false
+	}
+
+	for _, argument := range version.Arguments.Game {
+		for _, rule := range argument.Rules {
+			if rule.Features == nil {
+				continue
+			}
+
+			if _, ok := (*rule.Features)[FeatQuickPlaySupport]; ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// legacyQuickPlayArgv returns arguments that versions without Quick Play support use to join the server on launch.
+func legacyQuickPlayArgv(q QuickPlay) ([]string, error) {
+	if q.Type != QuickPlayMultiplayer {
+		return nil, &QuickPlayUnsupportedError{Type: q.Type}
+	}
+
+	host, port, err := SplitServerAddress(q.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{"--server", host, "--port", strconv.Itoa(port)}, nil
+}
+
+// quickPlayLogPath returns path where the game writes the log of the Quick Play session.
+func quickPlayLogPath(gameDirectory string, t time.Time) string {
+	return filepath.Join(gameDirectory, "quickPlay", "java", strconv.FormatInt(t.UnixMilli(), 10)+".json")
+}
@@ -0,0 +1,17 @@
+package launcher
+
+import "fmt"
+
+// QuickPlayUnsupportedError is returned when the version cannot join the requested type of target on launch.
+type QuickPlayUnsupportedError struct {
+	Type QuickPlayType
+}
+
+func (e *QuickPlayUnsupportedError) Error() string {
+	return fmt.Sprintf("version does not support joining %s target on launch", e.Type)
+}
+
+func (e *QuickPlayUnsupportedError) Is(target error) bool {
+	t, ok := target.(*QuickPlayUnsupportedError)
+	return ok && (t.Type == "" || e.Type == t.Type)
+}
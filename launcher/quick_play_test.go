@@ -0,0 +1,58 @@
+package launcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitServerAddress(t *testing.T) {
+	cases := []struct {
+		addr string
+		host string
+		port int
+	}{
+		{"mc.example.com", "mc.example.com", 25565},
+		{"mc.example.com:25566", "mc.example.com", 25566},
+		{"[::1]:25567", "::1", 25567},
+	}
+
+	for _, c := range cases {
+		host, port, err := SplitServerAddress(c.addr)
+		if assert.NoError(t, err, c.addr) {
+			assert.Equal(t, c.host, host, c.addr)
+			assert.Equal(t, c.port, port, c.addr)
+		}
+	}
+
+	_, _, err := SplitServerAddress("mc.example.com:abc")
+	assert.Error(t, err)
+}
+
+func TestLegacyQuickPlayArgv(t *testing.T) {
+	argv, err := legacyQuickPlayArgv(QuickPlay{Type: QuickPlayMultiplayer, Target: "mc.example.com"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"--server", "mc.example.com", "--port", "25565"}, argv)
+	}
+
+	_, err = legacyQuickPlayArgv(QuickPlay{Type: QuickPlaySingleplayer, Target: "New World"})
+	assert.ErrorIs(t, err, &QuickPlayUnsupportedError{})
+}
+
+func TestSupportsQuickPlay(t *testing.T) {
+	assert.False(t, supportsQuickPlay(Version{}))
+
+	assert.True(t, supportsQuickPlay(Version{
+		Arguments: &Arguments{
+			Game: []Argument{
+				{
+					Rules: Rules{{
+						Action:   Allow,
+						Features: &map[Feature]bool{FeatQuickPlaySupport: true},
+					}},
+					Value: []string{"--quickPlayPath", "${quickPlayPath}"},
+				},
+			},
+		},
+	}))
+}
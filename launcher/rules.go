@@ -72,8 +72,12 @@ func (r *RuleAction) Invert() RuleAction {
 type Feature string
 
 const (
-	FeatDemoUser         Feature = "is_demo_user"
-	FeatCustomResolution Feature = "has_custom_resolution"
+	FeatDemoUser              Feature = "is_demo_user"
+	FeatCustomResolution      Feature = "has_custom_resolution"
+	FeatQuickPlaySupport      Feature = "has_quick_plays_support"
+	FeatQuickPlaySingleplayer Feature = "is_quick_play_singleplayer"
+	FeatQuickPlayMultiplayer  Feature = "is_quick_play_multiplayer"
+	FeatQuickPlayRealms       Feature = "is_quick_play_realms"
 )
 
 type Rule struct {
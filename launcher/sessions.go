@@ -0,0 +1,97 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brawaru/marct/utils"
+	"github.com/brawaru/marct/utils/osfile"
+)
+
+// anonymousProfileID is used as a directory name for sessions launched without a profile.
+const anonymousProfileID = "_"
+
+// SessionLogsPath returns path where log files of the game sessions are stored.
+func (w *Instance) SessionLogsPath() string {
+	return filepath.Join(w.Path, filepath.FromSlash(sessionLogsPath))
+}
+
+func (w *Instance) sessionsPath(profileID string) (string, error) {
+	if profileID == "" {
+		profileID = anonymousProfileID
+	}
+
+	if err := validateID(profileID); err != nil {
+		return "", fmt.Errorf("profile id %q: %w", profileID, err)
+	}
+
+	return filepath.Join(w.Path, filepath.FromSlash(sessionsPath), profileID), nil
+}
+
+// WriteSessionResult persists the result of the session under the directory of the profile it belongs to.
+func (w *Instance) WriteSessionResult(result SessionResult) error {
+	dir, err := w.sessionsPath(result.ProfileID)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(dir, result.ID+".json")
+
+	f, err := osfile.New(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+
+	defer utils.DClose(f)
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(result); err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+
+	return f.Sync()
+}
+
+// ReadSessionResults reads all persisted session results of the profile, sorted from the oldest to the newest.
+func (w *Instance) ReadSessionResults(profileID string) ([]SessionResult, error) {
+	dir, err := w.sessionsPath(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if utils.DoesNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read dir %s: %w", dir, err)
+	}
+
+	var results []SessionResult
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		var r *SessionResult
+		if err := unmarshalJSONFile(filepath.Join(dir, entry.Name()), &r); err != nil {
+			return nil, err
+		}
+
+		results = append(results, *r)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].StartedAt.Before(results[j].StartedAt)
+	})
+
+	return results, nil
+}
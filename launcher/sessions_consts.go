@@ -0,0 +1,37 @@
+package launcher
+
+const (
+	// Path where session records reside.
+	//
+	// It is followed by /{profileID}/{sessionID}.json
+	//
+	// Where:
+	//
+	// - profileID is identifier of the profile that was launched
+	//
+	// - sessionID is identifier of the session, prefixed with its start time for sorting.
+	sessionsPath = "marct_sessions"
+	// Path where records of the sessions running in background reside.
+	//
+	// It is followed by /{sessionID}.json
+	//
+	// Where:
+	//
+	// - sessionID is identifier of the session.
+	runningSessionsPath = "marct_running"
+	// Path where logs of the game sessions written by marct reside.
+	//
+	// It is followed by /marct-session-{time}.log
+	//
+	// Where:
+	//
+	// - time is the time when the log was created.
+	sessionLogsPath = "logs"
+	// Prefix of the session log file names.
+	sessionLogPrefix = "marct-session-"
+	// Directory within the game directory where Minecraft writes crash reports.
+	crashReportsDir = "crash-reports"
+)
+
+// SessionLogsKeep is the number of session log files retained in the logs directory.
+const SessionLogsKeep = 10
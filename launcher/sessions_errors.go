@@ -0,0 +1,35 @@
+package launcher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SessionNotFoundError is returned when no running session matches the query.
+type SessionNotFoundError struct {
+	Query string
+}
+
+func (e *SessionNotFoundError) Error() string {
+	return fmt.Sprintf("session %q is not running", e.Query)
+}
+
+func (e *SessionNotFoundError) Is(target error) bool {
+	t, ok := target.(*SessionNotFoundError)
+	return ok && (t.Query == "" || e.Query == t.Query)
+}
+
+// AmbiguousSessionError is returned when multiple running sessions match the query.
+type AmbiguousSessionError struct {
+	Query   string
+	Matches []string // Identifiers of the matching sessions.
+}
+
+func (e *AmbiguousSessionError) Error() string {
+	return fmt.Sprintf("session %q is ambiguous, it matches: %s", e.Query, strings.Join(e.Matches, ", "))
+}
+
+func (e *AmbiguousSessionError) Is(target error) bool {
+	t, ok := target.(*AmbiguousSessionError)
+	return ok && (t.Query == "" || e.Query == t.Query)
+}
@@ -0,0 +1,200 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brawaru/marct/utils"
+	"github.com/brawaru/marct/utils/osfile"
+)
+
+func (w *Instance) runningSessionsPath() string {
+	return filepath.Join(w.Path, filepath.FromSlash(runningSessionsPath))
+}
+
+func (w *Instance) runningSessionPath(id string) (string, error) {
+	if err := validateID(id); err != nil {
+		return "", fmt.Errorf("session id %q: %w", id, err)
+	}
+
+	return filepath.Join(w.runningSessionsPath(), id+".json"), nil
+}
+
+func (w *Instance) writeRunningSession(s RunningSession) error {
+	name, err := w.runningSessionPath(s.ID)
+	if err != nil {
+		return err
+	}
+
+	f, err := osfile.New(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+
+	defer utils.DClose(f)
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+
+	return f.Sync()
+}
+
+// unregisterSession removes the record of the session without releasing its resources.
+func (w *Instance) unregisterSession(id string) error {
+	name, err := w.runningSessionPath(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !utils.DoesNotExist(err) {
+		return fmt.Errorf("remove %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// removeRunningSession removes the record of the session that is no longer running and releases its resources.
+func (w *Instance) removeRunningSession(s RunningSession) error {
+	if s.NativesDirectory != "" {
+		if err := w.releaseNatives(s.NativesDirectory, s.PID); err != nil {
+			return fmt.Errorf("release natives: %w", err)
+		}
+	}
+
+	if s.GameDirectory != "" {
+		if err := unlockGameDirectory(s.GameDirectory, s.PID); err != nil {
+			return fmt.Errorf("unlock game directory: %w", err)
+		}
+	}
+
+	return w.unregisterSession(s.ID)
+}
+
+// ReadRunningSessions reads records of the running sessions, both the ones running in background and in foreground,
+// sorted from the oldest to the newest. Records of the sessions whose process is no longer alive are pruned.
+func (w *Instance) ReadRunningSessions() ([]RunningSession, error) {
+	dir := w.runningSessionsPath()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if utils.DoesNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read dir %s: %w", dir, err)
+	}
+
+	var sessions []RunningSession
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		var s *RunningSession
+		if err := unmarshalJSONFile(filepath.Join(dir, entry.Name()), &s); err != nil {
+			return nil, err
+		}
+
+		if !s.Alive() {
+			if err := w.removeRunningSession(*s); err != nil {
+				return nil, fmt.Errorf("prune session %s: %w", s.ID, err)
+			}
+
+			continue
+		}
+
+		sessions = append(sessions, *s)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+	})
+
+	return sessions, nil
+}
+
+// RunningSessionLogs returns log files of the sessions running in background, which must be kept while they run.
+func (w *Instance) RunningSessionLogs() ([]string, error) {
+	sessions, err := w.ReadRunningSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []string
+	for _, s := range sessions {
+		if s.LogFile != "" {
+			logs = append(logs, s.LogFile)
+		}
+	}
+
+	return logs, nil
+}
+
+// FindRunningSession finds the session running in background by its PID, identifier or unique prefix of the identifier.
+func (w *Instance) FindRunningSession(query string) (*RunningSession, error) {
+	sessions, err := w.ReadRunningSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []RunningSession
+
+	for _, s := range sessions {
+		if s.Foreground {
+			continue
+		}
+
+		if s.ID == query || strconv.Itoa(s.PID) == query {
+			return &s, nil
+		}
+
+		if strings.HasPrefix(s.ID, query) {
+			matches = append(matches, s)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, &SessionNotFoundError{Query: query}
+	case 1:
+		return &matches[0], nil
+	default:
+		var ids []string
+		for _, m := range matches {
+			ids = append(ids, m.ID)
+		}
+
+		return nil, &AmbiguousSessionError{Query: query, Matches: ids}
+	}
+}
+
+// KillSession terminates the process of the session running in background. If force is true, the process is killed
+// without giving it a chance to save the game. Sessions whose process is no longer running are reported as
+// SessionNotFoundError, so that the process given the same PID is not signalled.
+func (w *Instance) KillSession(s RunningSession, force bool) error {
+	if !s.Alive() {
+		return &SessionNotFoundError{Query: s.ID}
+	}
+
+	if err := terminateProcess(s.PID, force); err != nil {
+		return fmt.Errorf("terminate process %d: %w", s.PID, err)
+	}
+
+	return nil
+}
+
+// Alive checks whether the process of the session is still running, and not replaced by another process given the same
+// PID.
+func (s *RunningSession) Alive() bool {
+	return processRunning(s.PID, s.ProcessStartTime)
+}
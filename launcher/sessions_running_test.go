@@ -0,0 +1,138 @@
+package launcher
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunningSessionsRegistry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on POSIX true command")
+	}
+
+	w := &Instance{Path: t.TempDir()}
+
+	exited := exec.Command("true")
+	if !assert.NoError(t, exited.Run()) {
+		return
+	}
+
+	natives := filepath.Join(w.Path, "bin", "dead")
+	if !assert.NoError(t, w.acquireNatives(natives, exited.ProcessState.Pid())) {
+		return
+	}
+
+	now := time.Now()
+
+	records := []RunningSession{
+		{ID: "20220101T000000-aaaaaaaa", PID: os.Getpid(), StartedAt: now.Add(-time.Hour)},
+		{ID: "20220101T000000-bbbbbbbb", PID: os.Getpid(), StartedAt: now},
+		{ID: "20220102T000000-cccccccc", PID: exited.ProcessState.Pid(), StartedAt: now, NativesDirectory: natives},
+	}
+
+	for _, r := range records {
+		if !assert.NoError(t, w.writeRunningSession(r)) {
+			return
+		}
+	}
+
+	sessions, err := w.ReadRunningSessions()
+	if !assert.NoError(t, err) || !assert.Len(t, sessions, 2) {
+		return
+	}
+
+	assert.Equal(t, records[0].ID, sessions[0].ID)
+	assert.NoFileExists(t, filepath.Join(w.nativesRefsPath("dead"), strconv.Itoa(exited.ProcessState.Pid())),
+		"natives of the dead session must be released")
+
+	s, err := w.FindRunningSession("20220101T000000-b")
+	if assert.NoError(t, err) {
+		assert.Equal(t, records[1].ID, s.ID)
+	}
+
+	_, err = w.FindRunningSession("20220101T")
+	assert.ErrorIs(t, err, &AmbiguousSessionError{})
+
+	_, err = w.FindRunningSession("20220102T000000-cccccccc")
+	assert.ErrorIs(t, err, &SessionNotFoundError{})
+}
+
+func TestRunningSessionReusedPID(t *testing.T) {
+	startTime, err := processStartTime(os.Getpid())
+	if err != nil {
+		t.Skip("process start time is not supported")
+	}
+
+	w := &Instance{Path: t.TempDir()}
+
+	// the game has exited and its PID has been given to this process since
+	s := RunningSession{ID: "20220101T000000-aaaaaaaa", PID: os.Getpid(), ProcessStartTime: startTime - 1}
+	if !assert.NoError(t, w.writeRunningSession(s)) {
+		return
+	}
+
+	assert.ErrorIs(t, w.KillSession(s, true), &SessionNotFoundError{}, "process given the same PID must not be signalled")
+
+	sessions, err := w.ReadRunningSessions()
+	if assert.NoError(t, err) {
+		assert.Empty(t, sessions, "record of the session must be pruned")
+	}
+}
+
+func TestLaunchResultCleanDetached(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+
+	// this process stands in for the game that keeps running in background
+	pid := os.Getpid()
+
+	proc, err := os.FindProcess(pid)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	natives := filepath.Join(w.Path, "bin", "detached")
+	gameDir := filepath.Join(w.Path, "game")
+	session := RunningSession{ID: "20220101T000000-aaaaaaaa", PID: pid, NativesDirectory: natives, GameDirectory: gameDir}
+
+	if !assert.NoError(t, w.acquireNatives(natives, pid)) ||
+		!assert.NoError(t, lockGameDirectory(gameDir, "profile", false)) ||
+		!assert.NoError(t, transferGameDirectory(gameDir, session.ID, pid)) ||
+		!assert.NoError(t, w.writeRunningSession(session)) {
+		return
+	}
+
+	r := &LaunchResult{
+		Command:          &exec.Cmd{Process: proc},
+		Session:          &session,
+		NativesDirectory: natives,
+		GameDirectory:    gameDir,
+		instance:         w,
+	}
+
+	// resources of the detached session are released once its record is pruned
+	if !assert.NoError(t, r.Clean()) {
+		return
+	}
+
+	assert.FileExists(t, filepath.Join(w.nativesRefsPath("detached"), strconv.Itoa(pid)), "natives must stay referenced")
+
+	assert.NoError(t, editGameDirOwner(gameDir, func(current *gameDirOwner) (*gameDirOwner, error) {
+		if assert.NotNil(t, current, "game directory must stay locked") {
+			assert.Equal(t, session.ID, current.SessionID)
+		}
+
+		return current, nil
+	}))
+
+	sessions, err := w.ReadRunningSessions()
+	if assert.NoError(t, err) && assert.Len(t, sessions, 1) {
+		assert.Equal(t, session.ID, sessions[0].ID)
+	}
+}
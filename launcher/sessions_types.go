@@ -0,0 +1,49 @@
+package launcher
+
+import "time"
+
+// SessionOutcome describes why the game session has ended.
+type SessionOutcome string
+
+const (
+	OutcomeNormalExit   SessionOutcome = "normal"        // Game has exited normally with zero exit code.
+	OutcomeCrashed      SessionOutcome = "crashed"       // Game has written a crash report during the session.
+	OutcomeJVMFailure   SessionOutcome = "jvm-failure"   // JVM has failed before the main class was loaded.
+	OutcomeKilled       SessionOutcome = "killed"        // Game process was terminated by a signal.
+	OutcomeAbnormalExit SessionOutcome = "abnormal-exit" // Game has exited with non-zero exit code without a crash report.
+)
+
+// SessionResult is a record of a finished game session.
+type SessionResult struct {
+	ID          string         `json:"id"`                    // Identifier of the session.
+	ProfileID   string         `json:"profileId,omitempty"`   // Identifier of the launched profile.
+	VersionID   string         `json:"versionId"`             // Identifier of the launched version.
+	PID         int            `json:"pid"`                   // Process ID of the game.
+	StartedAt   time.Time      `json:"startedAt"`             // Time when the game process was started.
+	StoppedAt   time.Time      `json:"stoppedAt"`             // Time when the game process has exited.
+	ExitCode    int            `json:"exitCode"`              // Exit code of the process, or -1 if it was killed by a signal.
+	Signal      string         `json:"signal,omitempty"`      // Name of the signal that has terminated the process, if any.
+	Outcome     SessionOutcome `json:"outcome"`               // Classified outcome of the session.
+	CrashReport string         `json:"crashReport,omitempty"` // Path to the crash report written during the session, if any.
+}
+
+// Duration returns how long the session has lasted.
+func (r *SessionResult) Duration() time.Duration {
+	return r.StoppedAt.Sub(r.StartedAt)
+}
+
+// RunningSession is a record of the game session running in background.
+type RunningSession struct {
+	ID               string    `json:"id"`                         // Identifier of the session.
+	ProfileID        string    `json:"profileId,omitempty"`        // Identifier of the launched profile.
+	VersionID        string    `json:"versionId"`                  // Identifier of the launched version.
+	Account          string    `json:"account"`                    // Name of the player the game was launched as.
+	PID              int       `json:"pid"`                        // Process ID of the game.
+	ProcessStartTime int64     `json:"processStartTime,omitempty"` // Start time of the game process, zero if unknown.
+	StartedAt        time.Time `json:"startedAt"`                  // Time when the game process was started.
+	LogFile          string    `json:"logFile"`                    // Path to the file where output of the game is written.
+	NativesDirectory string    `json:"nativesDirectory"`           // Directory with natives the session holds a reference to.
+	GameDirectory    string    `json:"gameDirectory"`              // Game directory locked by the session.
+	JavaPath         string    `json:"javaPath,omitempty"`         // Path to the Java executable running the game.
+	Foreground       bool      `json:"foreground,omitempty"`       // Whether the output is printed by the launcher instead of LogFile.
+}
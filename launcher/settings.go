@@ -21,6 +21,91 @@ type Settings struct {
 		PassCmd string               `mapstructure:"pass-cmd"`
 		PassDir string               `mapstructure:"pass-dir"`
 	} `mapstructure:"keyring"`
+	// Settings of the profiles that Minecraft Launcher does not know about, keyed by profile identifier.
+	Profiles map[string]ProfileSettings `mapstructure:"profiles" toml:"profiles,omitempty"`
+	// Builds of the runtimes to install instead of the most recent ones, keyed by runtime component.
+	JavaPins map[string]string `mapstructure:"java-pins" toml:"java-pins,omitempty"`
+	// Settings of the Java runtimes installation.
+	JRE JRESettings `mapstructure:"jre" toml:"jre,omitempty"`
+	// Settings of the network access.
+	Network NetworkSettings `mapstructure:"network" toml:"network,omitempty"`
+}
+
+// JRESettings configure where Java runtimes are installed from.
+type JRESettings struct {
+	Provider    string `mapstructure:"provider" toml:"provider,omitempty"`         // Provider to install runtimes from, empty to choose automatically.
+	AdoptiumURL string `mapstructure:"adoptium-url" toml:"adoptium-url,omitempty"` // Base URL of the Adoptium API, e.g. of a local mirror.
+}
+
+// NetworkSettings configure how the network is accessed.
+type NetworkSettings struct {
+	Mirrors []MirrorSettings `mapstructure:"mirrors" toml:"mirrors,omitempty"`   // Mirrors to use instead of the official servers.
+	Proxy   ProxySettings    `mapstructure:"proxy" toml:"proxy,omitempty"`       // Proxy to send requests through.
+	CAFiles []string         `mapstructure:"ca-files" toml:"ca-files,omitempty"` // PEM bundles of certificates trusted in addition to the system ones.
+}
+
+// ProxySettings configure the proxy all requests are sent through.
+type ProxySettings struct {
+	URL     string   `mapstructure:"url" toml:"url,omitempty"`           // URL of HTTP, HTTPS or SOCKS5 proxy, empty to use the proxy from environment.
+	User    string   `mapstructure:"user" toml:"user,omitempty"`         // User to authenticate as, the password is kept in the keyring.
+	NoProxy []string `mapstructure:"no-proxy" toml:"no-proxy,omitempty"` // Hosts requested directly, a leading dot matches subdomains too.
+}
+
+// MirrorSettings configure the mirrors of the server.
+type MirrorSettings struct {
+	Prefix     string   `mapstructure:"prefix" toml:"prefix"`                     // Beginning of the URLs to rewrite, a URL, a host or a name of the official server.
+	URLs       []string `mapstructure:"urls" toml:"urls"`                         // Replacements of the prefix, tried in order.
+	SkipOrigin bool     `mapstructure:"skip-origin" toml:"skip-origin,omitempty"` // Whether the original URL is not tried once all mirrors fail.
+}
+
+// ProfileSettings are marct-specific settings of the profile, which are kept out of the launcher profiles file.
+type ProfileSettings struct {
+	Wrapper    string            `mapstructure:"wrapper" toml:"wrapper,omitempty"`         // Command the game is run through, e.g. "gamemoderun".
+	Env        map[string]string `mapstructure:"env" toml:"env,omitempty"`                 // Environment variables set for the game process.
+	MemoryHint MemoryHint        `mapstructure:"memory-hint" toml:"memory-hint,omitempty"` // How demanding the game is, used to size the heap.
+}
+
+// IsEmpty checks whether none of the settings is set.
+func (p ProfileSettings) IsEmpty() bool {
+	return p.Wrapper == "" && len(p.Env) == 0 && p.MemoryHint == ""
+}
+
+// Profile returns marct-specific settings of the profile.
+func (s *Settings) Profile(id string) ProfileSettings {
+	return s.Profiles[id]
+}
+
+// SetProfile replaces marct-specific settings of the profile, removing them entirely if they are empty.
+func (s *Settings) SetProfile(id string, p ProfileSettings) {
+	if p.IsEmpty() {
+		delete(s.Profiles, id)
+		return
+	}
+
+	if s.Profiles == nil {
+		s.Profiles = map[string]ProfileSettings{}
+	}
+
+	s.Profiles[id] = p
+}
+
+// JavaPin returns the build the runtime component is pinned to, or empty string if the most recent build is used.
+func (s *Settings) JavaPin(component string) string {
+	return s.JavaPins[component]
+}
+
+// SetJavaPin pins the runtime component to the build, empty build removes the pin.
+func (s *Settings) SetJavaPin(component string, build string) {
+	if build == "" {
+		delete(s.JavaPins, component)
+		return
+	}
+
+	if s.JavaPins == nil {
+		s.JavaPins = map[string]string{}
+	}
+
+	s.JavaPins[component] = build
 }
 
 type SettingsFile struct {
@@ -0,0 +1,36 @@
+package launcher
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingsProfilesPersistence(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "marct_settings.toml")
+
+	s := NewSettings(name)
+	s.Version = 2
+	s.SetProfile("vanilla", ProfileSettings{
+		Wrapper: "gamemoderun",
+		Env:     map[string]string{"__GL_THREADED_OPTIMIZATIONS": "1"},
+	})
+	s.SetProfile("empty", ProfileSettings{})
+
+	if !assert.NoError(t, s.Save()) {
+		return
+	}
+
+	r := NewSettings(name)
+	if !assert.NoError(t, r.Read()) {
+		return
+	}
+
+	assert.Equal(t, ProfileSettings{
+		Wrapper: "gamemoderun",
+		Env:     map[string]string{"__GL_THREADED_OPTIMIZATIONS": "1"},
+	}, r.Profile("vanilla"))
+	assert.True(t, r.Profile("empty").IsEmpty())
+	assert.NotContains(t, r.Profiles, "empty")
+}
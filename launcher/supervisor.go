@@ -0,0 +1,213 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/brawaru/marct/locales"
+	"github.com/brawaru/marct/utils"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// Supervisor owns the game process, tracks its lifetime and classifies the outcome of the session once it ends.
+type Supervisor struct {
+	Command       *exec.Cmd // Command of the game process.
+	ProfileID     string    // Identifier of the launched profile.
+	VersionID     string    // Identifier of the launched version.
+	GameDirectory string    // Directory where game stores its files, crash reports are looked up in there.
+
+	instance  *Instance
+	stdout    io.Writer // Original output of the process.
+	id        string
+	startedAt time.Time
+	loaded    uint32 // Set to 1 once the game has written anything to stdout, meaning the main class has loaded.
+	detached  bool   // Whether the process outlives the launcher, its output must not be intercepted then.
+}
+
+// flusher is implemented by output processors that buffer incomplete data, like Log4JWriter.
+type flusher interface {
+	Flush() error
+}
+
+// loadWatcher is a writer that marks the supervised game as loaded on the first write.
+type loadWatcher struct {
+	s *Supervisor
+	w io.Writer
+}
+
+func (l *loadWatcher) Write(p []byte) (int, error) {
+	atomic.StoreUint32(&l.s.loaded, 1)
+	return l.w.Write(p)
+}
+
+func newSessionID(t time.Time) string {
+	return t.UTC().Format("20060102T150405") + "-" + utils.NewUUID()[:8]
+}
+
+func (w *Instance) newSupervisor(cmd *exec.Cmd, profileID string, versionID string, gameDirectory string) *Supervisor {
+	return &Supervisor{
+		Command:       cmd,
+		ProfileID:     profileID,
+		VersionID:     versionID,
+		GameDirectory: gameDirectory,
+		instance:      w,
+	}
+}
+
+// ID returns identifier of the session. It is only available after the process has started.
+func (s *Supervisor) ID() string {
+	return s.id
+}
+
+// StartedAt returns the time when the process has started.
+func (s *Supervisor) StartedAt() time.Time {
+	return s.startedAt
+}
+
+// Start starts the supervised process.
+func (s *Supervisor) Start() error {
+	if !s.detached {
+		stdout := s.Command.Stdout
+		if stdout == nil {
+			stdout = io.Discard
+		}
+		s.stdout = stdout
+		s.Command.Stdout = &loadWatcher{s, stdout}
+	}
+
+	s.startedAt = time.Now()
+	s.id = newSessionID(s.startedAt)
+
+	if err := s.Command.Start(); err != nil {
+		return fmt.Errorf("start process: %w", err)
+	}
+
+	return nil
+}
+
+// findCrashReport looks for the newest crash report written in the game directory since the provided time.
+func findCrashReport(gameDirectory string, since time.Time) (string, error) {
+	dir := filepath.Join(gameDirectory, crashReportsDir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if utils.DoesNotExist(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("read dir %s: %w", dir, err)
+	}
+
+	// some filesystems store modification time with the precision of seconds
+	since = since.Truncate(time.Second)
+
+	var newest string
+	var newestTime time.Time
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".txt" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return "", fmt.Errorf("stat %s: %w", entry.Name(), err)
+		}
+
+		mt := info.ModTime()
+		if mt.Before(since) || mt.Before(newestTime) {
+			continue
+		}
+
+		newest = filepath.Join(dir, entry.Name())
+		newestTime = mt
+	}
+
+	return newest, nil
+}
+
+// classify decides the outcome of the session based on its result.
+func (s *Supervisor) classify(r *SessionResult) SessionOutcome {
+	switch {
+	case r.CrashReport != "":
+		return OutcomeCrashed
+	case r.Signal != "":
+		return OutcomeKilled
+	case r.ExitCode == 0:
+		return OutcomeNormalExit
+	case atomic.LoadUint32(&s.loaded) == 0:
+		return OutcomeJVMFailure
+	default:
+		return OutcomeAbnormalExit
+	}
+}
+
+// Wait waits for the supervised process to exit, classifies and persists the outcome of the session.
+//
+// Non-zero exit codes are not considered an error, they are reported in the result instead. If the crash report cannot
+// be looked up or the result cannot be persisted, the result is still returned together with the error.
+func (s *Supervisor) Wait() (*SessionResult, error) {
+	if waitErr := s.Command.Wait(); waitErr != nil {
+		var e *exec.ExitError
+		if !errors.As(waitErr, &e) {
+			return nil, fmt.Errorf("wait: %w", waitErr)
+		}
+	}
+
+	if f, ok := s.stdout.(flusher); ok {
+		if err := f.Flush(); err != nil {
+			return nil, fmt.Errorf("flush output: %w", err)
+		}
+	}
+
+	state := s.Command.ProcessState
+
+	r := &SessionResult{
+		ID:        s.id,
+		ProfileID: s.ProfileID,
+		VersionID: s.VersionID,
+		PID:       state.Pid(),
+		StartedAt: s.startedAt,
+		StoppedAt: time.Now(),
+		ExitCode:  state.ExitCode(),
+	}
+
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		r.Signal = ws.Signal().String()
+	}
+
+	// the session has run regardless, so its outcome is recorded without the crash report
+	crashReport, crashErr := findCrashReport(s.GameDirectory, s.startedAt)
+	if crashErr != nil {
+		warnCrashReportFailed(crashErr)
+		crashErr = fmt.Errorf("find crash report: %w", crashErr)
+	}
+	r.CrashReport = crashReport
+
+	r.Outcome = s.classify(r)
+
+	if err := s.instance.WriteSessionResult(*r); err != nil {
+		return r, fmt.Errorf("write session result: %w", err)
+	}
+
+	return r, crashErr
+}
+
+func warnCrashReportFailed(err error) {
+	println(locales.TranslateUsing(&i18n.LocalizeConfig{
+		TemplateData: map[string]string{
+			"Error": err.Error(),
+		},
+		DefaultMessage: &i18n.Message{
+			ID:    "warning.crash-report-failed",
+			Other: "failed to look up the crash report: {{ .Error }}",
+		},
+	}))
+}
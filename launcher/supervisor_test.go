@@ -0,0 +1,123 @@
+package launcher
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCrashReport(t *testing.T) {
+	gameDir := t.TempDir()
+	dir := filepath.Join(gameDir, crashReportsDir)
+
+	if !assert.NoError(t, os.MkdirAll(dir, 0755)) {
+		return
+	}
+
+	start := time.Now()
+
+	old := filepath.Join(dir, "crash-old-client.txt")
+	if !assert.NoError(t, os.WriteFile(old, []byte("old"), 0644)) {
+		return
+	}
+	if !assert.NoError(t, os.Chtimes(old, start.Add(-time.Hour), start.Add(-time.Hour))) {
+		return
+	}
+
+	r, err := findCrashReport(gameDir, start)
+	if !assert.NoError(t, err) || !assert.Empty(t, r, "reports from before the session must be ignored") {
+		return
+	}
+
+	fresh := filepath.Join(dir, "crash-new-client.txt")
+	if !assert.NoError(t, os.WriteFile(fresh, []byte("new"), 0644)) {
+		return
+	}
+
+	r, err = findCrashReport(gameDir, start)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, fresh, r)
+}
+
+func TestSupervisorClassify(t *testing.T) {
+	s := &Supervisor{}
+
+	assert.Equal(t, OutcomeNormalExit, s.classify(&SessionResult{ExitCode: 0}))
+	assert.Equal(t, OutcomeJVMFailure, s.classify(&SessionResult{ExitCode: 1}))
+	assert.Equal(t, OutcomeKilled, s.classify(&SessionResult{ExitCode: -1, Signal: "killed"}))
+	assert.Equal(t, OutcomeCrashed, s.classify(&SessionResult{ExitCode: 0, CrashReport: "crash.txt"}))
+
+	s.loaded = 1
+
+	assert.Equal(t, OutcomeAbnormalExit, s.classify(&SessionResult{ExitCode: 1}))
+}
+
+func TestSessionResultsPersistence(t *testing.T) {
+	w := &Instance{Path: t.TempDir()}
+
+	start := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, outcome := range []SessionOutcome{OutcomeCrashed, OutcomeNormalExit} {
+		started := start.Add(time.Duration(i) * time.Hour)
+		err := w.WriteSessionResult(SessionResult{
+			ID:        newSessionID(started),
+			ProfileID: "profile",
+			VersionID: "1.18.2",
+			StartedAt: started,
+			StoppedAt: started.Add(time.Minute),
+			Outcome:   outcome,
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	results, err := w.ReadSessionResults("profile")
+	if !assert.NoError(t, err) || !assert.Len(t, results, 2) {
+		return
+	}
+
+	assert.Equal(t, OutcomeCrashed, results[0].Outcome)
+	assert.Equal(t, OutcomeNormalExit, results[1].Outcome)
+	assert.Equal(t, time.Minute, results[1].Duration())
+}
+
+func TestSupervisorWaitCrashReportUnreadable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on POSIX false command")
+	}
+
+	w := &Instance{Path: t.TempDir()}
+	gameDir := t.TempDir()
+
+	// crash reports cannot be listed, but the session has still run
+	if !assert.NoError(t, os.WriteFile(filepath.Join(gameDir, crashReportsDir), nil, 0644)) {
+		return
+	}
+
+	s := w.newSupervisor(exec.Command("false"), "profile", "1.18.2", gameDir)
+	if !assert.NoError(t, s.Start()) {
+		return
+	}
+
+	r, err := s.Wait()
+	assert.Error(t, err)
+
+	if assert.NotNil(t, r) {
+		assert.Equal(t, 1, r.ExitCode)
+		assert.Equal(t, OutcomeJVMFailure, r.Outcome)
+	}
+
+	results, err := w.ReadSessionResults("profile")
+	if assert.NoError(t, err) && assert.Len(t, results, 1) {
+		assert.Equal(t, OutcomeJVMFailure, results[0].Outcome)
+	}
+}
@@ -1,7 +1,6 @@
 package launcher
 
 import (
-	"crypto/sha1"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -10,9 +9,7 @@ import (
 	"github.com/brawaru/marct/globstate"
 	"github.com/brawaru/marct/launcher/download"
 	"github.com/brawaru/marct/locales"
-	"github.com/brawaru/marct/network"
 	"github.com/brawaru/marct/utils/slices"
-	"github.com/brawaru/marct/validfile"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 )
 
@@ -158,67 +155,47 @@ func (w *Instance) ReadVersionWithInherits(id string) (*Version, error) {
 	return nil, errors.New("no versions to inherit")
 }
 
-func (w *Instance) downloadClientJar(versionFile Version) error {
+// clientJarDownload returns download of the client JAR, or DownloadUnavailableError if the version has none.
+func (w *Instance) clientJarDownload(versionFile Version) (*download.Download, error) {
 	downloads := versionFile.Downloads
 
 	if downloads == nil {
-		return &DownloadUnavailableError{"client"}
+		return nil, &DownloadUnavailableError{"client"}
 	}
 
 	clientDownload, hasClientDownload := downloads["client"]
 
 	if !hasClientDownload {
-		return &DownloadUnavailableError{"client"}
+		return nil, &DownloadUnavailableError{"client"}
 	}
 
 	clientJarPath, err := w.VersionFilePath(versionFile.ID, "jar")
 	if err != nil {
-		return fmt.Errorf("cannot get path for client JAR: %w", err)
+		return nil, fmt.Errorf("cannot get path for client JAR: %w", err)
 	}
 
-	shouldDownload := false
-
-	if clientDownload.SHA1 == "" {
-		exists, existsErr := validfile.FileExists(clientJarPath)
-
-		if existsErr != nil {
-			return existsErr
-		}
-
-		shouldDownload = !exists
-	} else {
-		validateErr := validfile.ValidateFileHex(clientJarPath, sha1.New(), clientDownload.SHA1)
-
-		if validateErr != nil {
-			var v *validfile.ValidateError
-
-			if errors.As(validateErr, &v) && v.Mismatch() {
-				shouldDownload = true
-			} else {
-				return validateErr
-			}
-		}
+	validation := download.WithExistenceCheck()
+	if clientDownload.SHA1 != "" {
+		validation = download.WithSHA1(clientDownload.SHA1)
 	}
 
-	if shouldDownload {
-		if _, err := network.Download(clientDownload.URL, clientJarPath); err != nil {
-			return err
-		}
+	return download.NewURL(clientDownload.URL, clientJarPath, validation, download.WithSize(clientDownload.Size))
+}
 
-		if clientDownload.SHA1 != "" {
-			if err := validfile.ValidateFileHex(clientJarPath, sha1.New(), clientDownload.SHA1); err != nil {
-				return err
-			}
-		}
+// DownloadVersion downloads the client JAR, libraries, assets and log configuration of the version, reporting the
+// progress through the manager. If manager is nil, downloads are run without reporting the progress.
+func (w *Instance) DownloadVersion(versionFile Version, manager *download.Manager) error {
+	// TODO: download all inherits if there any
+
+	if manager == nil {
+		manager = download.NewManager(download.DefaultConcurrency)
 	}
 
-	return nil
-}
+	w.sweepParts()
 
-func (w *Instance) DownloadVersion(versionFile Version) error {
-	// TODO: download all inherits if there any
+	var downloads []*download.Download
 
-	clientJarDlErr := w.downloadClientJar(versionFile)
+	clientJar, clientJarDlErr := w.clientJarDownload(versionFile)
 	if clientJarDlErr != nil {
 		if errors.Is(clientJarDlErr, &DownloadUnavailableError{}) {
 			if globstate.VerboseLogs {
@@ -235,17 +212,23 @@ func (w *Instance) DownloadVersion(versionFile Version) error {
 		} else {
 			return clientJarDlErr
 		}
+	} else {
+		downloads = append(downloads, clientJar)
 	}
 
 	if versionFile.Libraries != nil {
-		if libDlErr := w.DownloadLibraries(versionFile.Libraries); libDlErr != nil {
+		libDownloads, libDlErr := w.librariesDownloads(versionFile.Libraries)
+		if libDlErr != nil {
 			return libDlErr
 		}
+
+		downloads = append(downloads, libDownloads...)
 	}
 
 	if versionFile.AssetIndex != nil {
 		indexDesc := *versionFile.AssetIndex
 
+		// index lists the assets, so it has to be downloaded before the rest
 		if indexDlErr := w.DownloadAssetIndex(indexDesc); indexDlErr != nil {
 			return indexDlErr
 		}
@@ -255,16 +238,22 @@ func (w *Instance) DownloadVersion(versionFile Version) error {
 			return readErr
 		}
 
-		if dlErr := w.DownloadAssets(*index); dlErr != nil {
-			return dlErr
+		assetDownloads, assetsErr := w.assetDownloads(*index)
+		if assetsErr != nil {
+			return assetsErr
 		}
+
+		downloads = append(downloads, assetDownloads...)
 	}
 
 	if logConfig, hasLogConfig := versionFile.Logging["client"]; hasLogConfig {
-		if logDlErr := w.DownloadLogConfig(logConfig); logDlErr != nil {
+		logDownload, logDlErr := w.logConfigDownload(logConfig)
+		if logDlErr != nil {
 			return logDlErr
 		}
+
+		downloads = append(downloads, logDownload)
 	}
 
-	return nil
+	return manager.Run(downloads)
 }
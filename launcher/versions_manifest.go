@@ -3,16 +3,11 @@ package launcher
 import (
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/brawaru/marct/network"
-	"github.com/brawaru/marct/utils"
-	"github.com/brawaru/marct/utils/osfile"
-	"github.com/brawaru/marct/validfile"
 )
 
 func (w *Instance) ReadVersions() (manifest *VersionsManifest, err error) {
@@ -47,46 +42,22 @@ func (w *Instance) FetchVersions(force bool) (manifest *VersionsManifest, err er
 		}
 	}
 
-	expired := force || (validfile.NotExpired(name, versionsManifestTTL) != nil)
-
-	if !expired {
-		return w.ReadVersions()
-	}
-
-	req, rawErr := http.NewRequest(http.MethodGet, versionsManifestURL, nil)
-	if rawErr != nil {
-		err = fmt.Errorf("create request: %w", rawErr)
-		return
-	}
-
-	resp, rawErr := network.PerformRequest(req, network.WithRetries())
-	if rawErr != nil {
-		err = fmt.Errorf("send request: %w", rawErr)
-		return
+	ttl := versionsManifestTTL
+	if force {
+		ttl = 0
 	}
 
-	defer utils.DClose(resp.Body)
-
-	manifestFile, rawErr := osfile.New(name)
-	if rawErr != nil {
-		err = fmt.Errorf("create file: %w", rawErr)
+	if _, rawErr := network.FetchCached(versionsManifestURL, name, ttl, network.WithRetries()); rawErr != nil {
+		err = fmt.Errorf("fetch %s: %w", versionsManifestURL, rawErr)
 		return
 	}
 
-	defer utils.DClose(manifestFile)
-
-	bodyReadWriter := io.TeeReader(resp.Body, manifestFile)
-
-	if rawErr := json.NewDecoder(bodyReadWriter).Decode(&manifest); rawErr != nil {
-		err = fmt.Errorf("decode response: %w", rawErr)
-		_ = manifestFile.Close()
-		_ = os.Remove(versionsManifestPath)
-		return
-	}
+	manifest, err = w.ReadVersions()
+	if err != nil {
+		// otherwise the broken manifest would be renewed by every conditional request
+		_ = network.RemoveCached(name)
 
-	_, rawErr = io.Copy(manifestFile, resp.Body)
-	if rawErr != nil {
-		err = fmt.Errorf("write response: %w", rawErr)
+		err = fmt.Errorf("read %s: %w", name, err)
 		return
 	}
 
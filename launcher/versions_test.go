@@ -3,6 +3,10 @@ package launcher
 import (
 	_ "embed"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/brawaru/marct/network"
 	"github.com/brawaru/marct/utils"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -51,3 +55,35 @@ func Test125(t *testing.T) {
 	jsonStr := string(encoded)
 	assert.NotEmpty(t, jsonStr, "should not be empty")
 }
+
+func TestFetchVersionsBroken(t *testing.T) {
+	conditional := 0
+	body := `{"versions": [`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	network.SetMirrors([]network.MirrorRule{{Prefix: "https://piston-meta.mojang.com/", Mirrors: []string{srv.URL + "/"}, SkipOrigin: true}})
+	defer network.SetMirrors(nil)
+
+	w := &Instance{Path: t.TempDir(), TemporalData: map[any]any{}}
+
+	_, err := w.FetchVersions(true)
+	assert.Error(t, err)
+
+	// manifest that cannot be decoded must not be confirmed by the conditional request
+	body = `{"versions": []}`
+
+	if _, err := w.FetchVersions(true); assert.NoError(t, err) {
+		assert.Zero(t, conditional)
+	}
+}
@@ -0,0 +1,194 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brawaru/marct/globstate"
+	"github.com/brawaru/marct/utils"
+)
+
+// cacheMetaSuffix is the suffix of the file next to the cached response, which keeps the validators of the response.
+const cacheMetaSuffix = ".httpcache"
+
+// cacheMeta keeps the validators of the cached response, which are sent to check whether it is still fresh.
+type cacheMeta struct {
+	URL          string `json:"url"`                     // URL the response has been received from.
+	ETag         string `json:"etag,omitempty"`          // Entity tag of the response.
+	LastModified string `json:"last-modified,omitempty"` // Modification date of the response.
+}
+
+func readCacheMeta(name string) (m cacheMeta, ok bool) {
+	b, err := os.ReadFile(name + cacheMetaSuffix)
+	if err != nil {
+		return
+	}
+
+	return m, json.Unmarshal(b, &m) == nil
+}
+
+// writeCacheMeta writes the validators of the response, even if there are none, so that the file can be found later.
+func writeCacheMeta(name string, m cacheMeta) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(name+cacheMetaSuffix, b, 0644)
+}
+
+// FetchCached makes sure the file with the name contains the response from the URL that is not older than ttl. Once
+// the file has expired, the request is sent with validators of the cached response, and if the server confirms that
+// it has not changed, only the modification time of the file is updated, renewing its ttl. Zero ttl makes the file
+// always expired. Returns whether the file has been rewritten with a new response.
+func FetchCached(url string, name string, ttl time.Duration, options ...Option) (updated bool, err error) {
+	stat, statErr := os.Stat(name)
+	exists := statErr == nil && stat.Mode().IsRegular()
+
+	if exists && time.Since(stat.ModTime()) < ttl {
+		return false, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+
+	if m, ok := readCacheMeta(name); exists && ok && m.URL == url {
+		if m.ETag != "" {
+			req.Header.Set("If-None-Match", m.ETag)
+		}
+
+		if m.LastModified != "" {
+			req.Header.Set("If-Modified-Since", m.LastModified)
+		}
+	}
+
+	resp, err := PerformRequest(req, options...)
+	if err != nil {
+		return false, fmt.Errorf("perform request: %w", err)
+	}
+
+	defer utils.DClose(resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && exists:
+		if globstate.VerboseLogs {
+			_, _ = fmt.Fprintf(os.Stderr, "%s has not been modified\n", url)
+		}
+
+		now := time.Now()
+		if err := os.Chtimes(name, now, now); err != nil {
+			return false, fmt.Errorf("renew %q: %w", name, err)
+		}
+
+		return false, nil
+	case !isSuccessful(resp.StatusCode):
+		return false, newHTTPStatusError(resp)
+	}
+
+	if err := writeResponse(resp.Body, name); err != nil {
+		return false, err
+	}
+
+	if err := writeCacheMeta(name, cacheMeta{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}); err != nil {
+		return true, fmt.Errorf("write validators of %q: %w", name, err)
+	}
+
+	return true, nil
+}
+
+// writeResponse writes the response body to the temporary file, which then replaces the file with the name.
+func writeResponse(body io.Reader, name string) error {
+	part := PartName(name)
+
+	file, err := createFile(part)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, body)
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(part, name)
+	}
+
+	if err != nil {
+		_ = os.Remove(part)
+		return fmt.Errorf("write %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// CachedFile is the file containing the response fetched with FetchCached.
+type CachedFile struct {
+	Name    string    // Path of the file.
+	URL     string    // URL the response has been received from.
+	Size    int64     // Size of the file.
+	Fetched time.Time // When the response has been received or last confirmed to be fresh.
+}
+
+// CachedFiles finds files fetched with FetchCached in the directories. Directories that do not exist are skipped.
+func CachedFiles(dirs ...string) ([]CachedFile, error) {
+	var files []CachedFile
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if utils.DoesNotExist(err) {
+				continue
+			}
+
+			return files, fmt.Errorf("read dir %q: %w", dir, err)
+		}
+
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), cacheMetaSuffix) {
+				continue
+			}
+
+			name := filepath.Join(dir, strings.TrimSuffix(e.Name(), cacheMetaSuffix))
+
+			m, _ := readCacheMeta(name)
+			f := CachedFile{Name: name, URL: m.URL}
+
+			if stat, err := os.Stat(name); err == nil {
+				f.Size = stat.Size()
+				f.Fetched = stat.ModTime()
+			}
+
+			files = append(files, f)
+		}
+	}
+
+	return files, nil
+}
+
+// RemoveCached removes the file fetched with FetchCached together with the validators of the response.
+func RemoveCached(name string) error {
+	for _, n := range []string{name, name + cacheMetaSuffix} {
+		if err := os.Remove(n); err != nil && !utils.DoesNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package network
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchCached(t *testing.T) {
+	requests, modified := 0, 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		modified++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("manifest"))
+	}))
+	defer srv.Close()
+
+	name := filepath.Join(t.TempDir(), "manifest.json")
+
+	updated, err := FetchCached(srv.URL, name, time.Hour)
+	if assert.NoError(t, err) {
+		assert.True(t, updated)
+	}
+
+	// still fresh, no request is sent
+	updated, err = FetchCached(srv.URL, name, time.Hour)
+	if assert.NoError(t, err) {
+		assert.False(t, updated)
+		assert.Equal(t, 1, requests)
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, os.Chtimes(name, old, old))
+
+	updated, err = FetchCached(srv.URL, name, time.Hour)
+	if assert.NoError(t, err) {
+		assert.False(t, updated)
+		assert.Equal(t, 2, requests)
+		assert.Equal(t, 1, modified)
+	}
+
+	if stat, err := os.Stat(name); assert.NoError(t, err) {
+		assert.True(t, stat.ModTime().After(old.Add(time.Hour)), "modification time must be renewed")
+	}
+
+	b, _ := os.ReadFile(name)
+	assert.Equal(t, "manifest", string(b))
+}
+
+func TestFetchCachedURLChanged(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	name := filepath.Join(t.TempDir(), "index.json")
+
+	_, err := FetchCached(srv.URL+"/a", name, 0)
+	assert.NoError(t, err)
+
+	updated, err := FetchCached(srv.URL+"/b", name, 0)
+	if assert.NoError(t, err) {
+		assert.True(t, updated, "validators of another URL must not be sent")
+	}
+
+	b, _ := os.ReadFile(name)
+	assert.Equal(t, "/b", string(b))
+}
+
+func TestFetchCachedError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	name := filepath.Join(t.TempDir(), "missing.json")
+
+	_, err := FetchCached(srv.URL, name, 0)
+	assert.True(t, errors.Is(err, &HTTPStatusError{StatusCode: http.StatusNotFound}))
+
+	_, statErr := os.Stat(name)
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestCachedFiles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	name := filepath.Join(dir, "cached.json")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other.json"), []byte("{}"), 0644))
+
+	_, err := FetchCached(srv.URL, name, 0)
+	assert.NoError(t, err)
+
+	files, err := CachedFiles(dir, filepath.Join(dir, "missing"))
+	if assert.NoError(t, err) && assert.Len(t, files, 1) {
+		assert.Equal(t, name, files[0].Name)
+		assert.Equal(t, srv.URL, files[0].URL)
+		assert.Equal(t, int64(4), files[0].Size)
+	}
+
+	assert.NoError(t, RemoveCached(name))
+
+	files, err = CachedFiles(dir)
+	if assert.NoError(t, err) {
+		assert.Empty(t, files)
+	}
+}
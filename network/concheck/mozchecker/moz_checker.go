@@ -48,8 +48,14 @@ func (c *mozChecker) Run() (concheck.CheckResult, error) {
 }
 
 func NewMozChecker() concheck.Checker {
+	return NewMozCheckerWithTransport(nil)
+}
+
+// NewMozCheckerWithTransport creates the checker which sends requests using the transport, e.g. through a proxy.
+func NewMozCheckerWithTransport(transport http.RoundTripper) concheck.Checker {
 	return &mozChecker{
 		client: http.Client{
+			Transport: transport,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				return http.ErrUseLastResponse
 			},
@@ -7,6 +7,8 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/brawaru/marct/network"
 	"github.com/brawaru/marct/utils"
@@ -30,6 +32,41 @@ type ResumeableDownload struct {
 	f            *os.File        // File where the response body will be written.
 	rangeSupport RangeSupport    // Whether byte serving is supported by the server.
 	retryCount   int             // Number of times the download has been retried. Reset every time a succesful request is made.
+	validator    string          // Entity tag or modification date of the content, used to resume the download.
+
+	progress   network.ProgressFunc      // Called with the number of bytes written, if set.
+	reported   int64                     // Number of bytes reported to progress, taken back when the file is rewound.
+	onResponse func(resp *http.Response) // Called with each successful response before its body is written, if set.
+}
+
+// Option is a function that configures the download.
+type Option func(r *ResumeableDownload)
+
+// WithProgress returns an option that sets the function called with the number of bytes written to the file, including
+// the bytes already present in the file when the download is resumed. When the file is rewound to be written from the
+// beginning, it is called with the negative number of bytes reported so far.
+func WithProgress(fn network.ProgressFunc) Option {
+	return func(r *ResumeableDownload) {
+		r.progress = fn
+	}
+}
+
+// WithResponseHook returns an option that sets the function called with each successful response before its body is
+// written to the file. It can be used to persist the validator of the response to resume the download later.
+func WithResponseHook(fn func(resp *http.Response)) Option {
+	return func(r *ResumeableDownload) {
+		r.onResponse = fn
+	}
+}
+
+// WithResume returns an option that continues writing after the bytes already present in the file, provided that the
+// server still has the content matching the validator, which is either an entity tag or a modification date. If the
+// content has changed, the download starts from the beginning.
+func WithResume(validator string) Option {
+	return func(r *ResumeableDownload) {
+		r.rangeSupport = RangeSupportYes
+		r.validator = validator
+	}
 }
 
 func (r *ResumeableDownload) open() error {
@@ -38,6 +75,16 @@ func (r *ResumeableDownload) open() error {
 		return fmt.Errorf("open file: %w", err)
 	}
 	r.f = f
+
+	if r.rangeSupport == RangeSupportYes {
+		written, err := f.Seek(0, io.SeekEnd)
+		if err != nil {
+			return &IOErr{fmt.Errorf("seek file: %w", err)}
+		}
+
+		r.report(written)
+	}
+
 	return nil
 }
 
@@ -101,6 +148,51 @@ func (e *RequestErr) Is(target error) bool {
 	return ok && errors.Is(t.Err, e.Err)
 }
 
+// Validator returns the validator suitable for resuming the download from the response, which is either a strong
+// entity tag or a modification date, or an empty string if the response has none.
+func Validator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return resp.Header.Get("Last-Modified")
+}
+
+// bodyReader remembers the error reading the response body, as opposed to the error writing to the file.
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		b.err = err
+	}
+	return n, err
+}
+
+// contentRangeStart returns the position of the first byte in the Content-Range header, or -1 if it cannot be parsed.
+func contentRangeStart(header string) int64 {
+	if !strings.HasPrefix(header, "bytes ") {
+		return -1
+	}
+
+	spec := strings.TrimPrefix(header, "bytes ")
+
+	i := strings.IndexByte(spec, '-')
+	if i < 0 {
+		return -1
+	}
+
+	start, err := strconv.ParseInt(spec[:i], 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return start
+}
+
 func (r *ResumeableDownload) downloadLoop() error {
 	// create a new request based off the base request
 	req := r.baseRequest.Clone(r.ctx)
@@ -114,9 +206,8 @@ func (r *ResumeableDownload) downloadLoop() error {
 	case RangeSupportNo:
 		fallthrough
 	case RangeSupportUnknown:
-		_, err := r.f.Seek(0, io.SeekStart)
-		if err != nil {
-			return &IOErr{fmt.Errorf("seek file: %w", err)}
+		if err := r.rewind(); err != nil {
+			return err
 		}
 	case RangeSupportYes:
 		currentPosition, err := r.f.Seek(0, io.SeekCurrent)
@@ -126,9 +217,14 @@ func (r *ResumeableDownload) downloadLoop() error {
 
 		if currentPosition != 0 {
 			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", currentPosition))
-		}
 
-		isResuming = true
+			// if the content has changed since, the server responds with the whole content instead
+			if r.validator != "" {
+				req.Header.Set("If-Range", r.validator)
+			}
+
+			isResuming = true
+		}
 	}
 
 	// send request
@@ -163,27 +259,83 @@ func (r *ResumeableDownload) downloadLoop() error {
 				Received: resp.StatusCode,
 			}
 		}
+
+		currentPosition, err := r.f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return &IOErr{fmt.Errorf("getpos: %w", err)}
+		}
+
+		if contentRangeStart(resp.Header.Get("Content-Range")) != currentPosition {
+			return &ServerMisconfiguredErr{
+				Expected: http.StatusPartialContent,
+				Received: resp.StatusCode,
+			}
+		}
 	case http.StatusOK:
+		if isResuming && (r.validator == "" || Validator(resp) == r.validator) {
+			return &ServerMisconfiguredErr{
+				Expected: http.StatusPartialContent,
+				Received: resp.StatusCode,
+			}
+		}
+
+		// either a fresh start, or the content has changed and is sent from the beginning
+		if err := r.truncate(); err != nil {
+			return err
+		}
+
+		r.validator = Validator(resp)
+	case http.StatusRequestedRangeNotSatisfiable:
 		if isResuming {
+			// the file is either complete or longer than the content now is, either way it's easier to start over
 			return &ServerMisconfiguredErr{
 				Expected: http.StatusPartialContent,
 				Received: resp.StatusCode,
 			}
 		}
+
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	// TODO: handle redirect response codes?
 	default:
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
+	if r.onResponse != nil {
+		r.onResponse(resp)
+	}
+
+	w := &progressWriter{w: r.f, progress: r.report}
+
 	// write whatever we have to the file
-	_, err = io.Copy(r.f, resp.Body)
+	body := &bodyReader{r: resp.Body}
+
+	_, err = io.Copy(w, body)
 	if err != nil {
+		if body.err != nil {
+			// connection has been interrupted, what's written can be kept and resumed
+			return &RequestErr{fmt.Errorf("read body: %w", err)}
+		}
+
 		return &IOErr{fmt.Errorf("copy: %w", err)}
 	}
 
 	return nil
 }
 
+// progressWriter reports the number of bytes written through it.
+type progressWriter struct {
+	w        io.Writer
+	progress network.ProgressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.progress(int64(n))
+	}
+	return n, err
+}
+
 // COA is a short for Corse of Action, is the code returned by determining function based on error that occured
 // during the download.
 type COA int
@@ -213,12 +365,49 @@ func (r *ResumeableDownload) determineCOA(err error) COA {
 }
 
 func (r *ResumeableDownload) reset() error {
-	_, err := r.f.Seek(0, io.SeekStart)
+	if err := r.rewind(); err != nil {
+		return err
+	}
 
-	if err != nil {
+	r.validator = ""
+
+	return nil
+}
+
+// report passes the number of bytes written to progress, if set, keeping the count of bytes reported so far.
+func (r *ResumeableDownload) report(written int64) {
+	if written == 0 {
+		return
+	}
+
+	r.reported += written
+
+	if r.progress != nil {
+		r.progress(written)
+	}
+}
+
+// rewind moves to the beginning of the file, taking back the progress reported for the bytes that will be overwritten.
+func (r *ResumeableDownload) rewind() error {
+	if _, err := r.f.Seek(0, io.SeekStart); err != nil {
 		return &IOErr{fmt.Errorf("seek file: %w", err)}
 	}
 
+	r.report(-r.reported)
+
+	return nil
+}
+
+// truncate discards everything written to the file, as the content is written from the beginning.
+func (r *ResumeableDownload) truncate() error {
+	if err := r.rewind(); err != nil {
+		return err
+	}
+
+	if err := r.f.Truncate(0); err != nil {
+		return &IOErr{fmt.Errorf("truncate file: %w", err)}
+	}
+
 	return nil
 }
 
@@ -260,12 +449,26 @@ dlLoop:
 	return nil
 }
 
-func NewResumeableDownload(req *http.Request, dest string, ctx context.Context) *ResumeableDownload {
+// NewResumeableDownload opens the destination file to write the response body to. The download must be closed once it
+// is no longer needed.
+func NewResumeableDownload(req *http.Request, dest string, ctx context.Context, options ...Option) (*ResumeableDownload, error) {
 	r := &ResumeableDownload{
 		baseRequest: req.Clone(req.Context()),
 		dest:        dest,
 		ctx:         ctx,
 	}
-	r.open()
-	return r
+
+	for _, option := range options {
+		option(r)
+	}
+
+	if err := r.open(); err != nil {
+		if r.f != nil {
+			utils.DClose(r.f)
+		}
+
+		return nil, err
+	}
+
+	return r, nil
 }
@@ -13,7 +13,9 @@ import (
 	"github.com/brawaru/marct/utils"
 )
 
+// tr is the transport shared by all requests, configured with ConfigureTransport.
 var tr = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
 	TLSClientConfig: &tls.Config{
 		Renegotiation:      tls.RenegotiateOnceAsClient,
 		InsecureSkipVerify: false,
@@ -35,18 +37,69 @@ func createFile(name string) (*os.File, error) {
 	return os.Create(name)
 }
 
-// Download sends a request to a given URL and writes response body to the destination. It does not account for status
-// codes and will write any body it receives without errors.
+// ProgressFunc is called with the number of bytes written each time a part of the response body is saved.
+type ProgressFunc func(written int64)
+
+// progressWriter reports the number of bytes written through it.
+type progressWriter struct {
+	w        io.Writer
+	progress ProgressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.progress(int64(n))
+	}
+	return n, err
+}
+
+// PartSuffix is the suffix of the temporary file the response body is written to before it is moved to the destination.
+const PartSuffix = ".part"
+
+// PartName returns the name of the temporary file for the destination.
+func PartName(dest string) string {
+	return dest + PartSuffix
+}
+
+// Download sends a request to a given URL and writes response body to the destination. Responses with status other
+// than 2xx are reported as HTTPStatusError and nothing is written.
 //
 // It will be removed in the future when the better APIs are available. Avoid using it.
 func Download(url string, dest string, options ...Option) (written int64, err error) {
+	return DownloadWithProgress(url, dest, nil, options...)
+}
+
+// DownloadWithProgress is like Download, but reports the progress of writing the response body, if progress is not nil.
+// The body is written to the temporary file first, which replaces the destination only once it is written completely.
+func DownloadWithProgress(url string, dest string, progress ProgressFunc, options ...Option) (written int64, err error) {
+	part := PartName(dest)
+
+	written, err = DownloadFile(url, part, progress, options...)
+	if err != nil {
+		_ = os.Remove(part)
+		return
+	}
+
+	if renameErr := os.Rename(part, dest); renameErr != nil {
+		_ = os.Remove(part)
+		err = fmt.Errorf("rename %q: %w", part, renameErr)
+	}
+
+	return
+}
+
+// DownloadFile writes the response body directly to the file with the name, reporting the progress if it's not nil. The
+// file is synced to the disk before returning, but not removed if writing fails. Responses with status other than 2xx
+// are reported as HTTPStatusError.
+func DownloadFile(url string, name string, progress ProgressFunc, options ...Option) (written int64, err error) {
 	r, e := http.NewRequest("GET", url, nil)
 	if e != nil {
 		err = fmt.Errorf("create request: %w", e)
 		return
 	}
 
-	resp, e := PerformRequest(r, options...)
+	resp, e := PerformRequest(r, append([]Option{WithStatusCheck()}, options...)...)
 
 	if e != nil {
 		err = fmt.Errorf("perform request: %w", e)
@@ -55,7 +108,7 @@ func Download(url string, dest string, options ...Option) (written int64, err er
 
 	defer utils.DClose(resp.Body)
 
-	file, createErr := createFile(dest)
+	file, createErr := createFile(name)
 
 	if createErr != nil {
 		return 0, createErr
@@ -63,13 +116,18 @@ func Download(url string, dest string, options ...Option) (written int64, err er
 
 	defer utils.DClose(file)
 
-	if written, err = io.Copy(file, resp.Body); err != nil {
+	var w io.Writer = file
+	if progress != nil {
+		w = &progressWriter{w: file, progress: progress}
+	}
+
+	if written, err = io.Copy(w, resp.Body); err != nil {
 		err = fmt.Errorf("write response: %w", err)
 		return
 	}
 
-	if err := file.Sync(); err != nil {
-		err = fmt.Errorf("sync file: %w", err)
+	if syncErr := file.Sync(); syncErr != nil {
+		err = fmt.Errorf("sync file: %w", syncErr)
 	}
 
 	return
@@ -79,12 +137,24 @@ func Download(url string, dest string, options ...Option) (written int64, err er
 // or ErrRetryRequest error, if request can be repeated. If nil is returned, then the next error handler is called.
 type ErrorHandler func(e error) error
 
+// ResponseHandler handles responses received during the execution of an action. It may return ErrRetryRequest error,
+// if request must be repeated, or any other error to report it instead of the response. If nil is returned, then the
+// next response handler is called. Handlers returning an error must not close the response body.
+type ResponseHandler func(resp *http.Response) error
+
 type ActionOptions struct {
 	// Error handlers defined in sequential order. If error occurs during the execution of an action, then the first
 	// handler is called, if it returns an error that is not ErrRetryRequest, then this error is returned to the
 	// caller of the action. If the error is ErrRetryRequest, then the action is repeated. If no error is returned,
 	// then the next handler is called. If no handlers handle the error, then the error is returned to the caller.
 	ErrorHandlers []ErrorHandler
+	// Response handlers defined in sequential order, called the same way as error handlers, but for each response.
+	ResponseHandlers []ResponseHandler
+	// Whether responses with status other than 2xx are reported as HTTPStatusError.
+	CheckStatus bool
+	// Maximum number of attempts, 0 means unlimited. Handlers are not called for the last attempt, so that it is not
+	// delayed or repeated.
+	MaxAttempts int
 	// HTTP client used to execute the action.
 	Client *http.Client
 }
@@ -97,7 +167,21 @@ var (
 	ErrRetryRequest = errors.New("retry request")
 )
 
+// PerformRequest sends the request, rewriting its URL according to the mirror rules set with SetMirrors. Transport is
+// configured first, if ConfigureTransportLazily has been used.
 func PerformRequest(request *http.Request, options ...Option) (*http.Response, error) {
+	if err := ensureTransport(); err != nil {
+		return nil, fmt.Errorf("configure transport: %w", err)
+	}
+
+	if urls := MirrorURLs(request.URL.String()); len(urls) != 1 || urls[0] != request.URL.String() {
+		return performMirrored(request, urls, options...)
+	}
+
+	return performRequest(request, options...)
+}
+
+func performRequest(request *http.Request, options ...Option) (*http.Response, error) {
 	o := &ActionOptions{
 		ErrorHandlers: []ErrorHandler{},
 		Client:        &DefaultClient,
@@ -107,25 +191,84 @@ func PerformRequest(request *http.Request, options ...Option) (*http.Response, e
 		option(request, o)
 	}
 
-	for {
+	for attempt := 0; ; attempt++ {
+		if attempt != 0 && request.GetBody != nil {
+			// body has been consumed by the previous attempt
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("get body: %w", err)
+			}
+
+			request.Body = body
+		}
+
 		resp, reqErr := o.Client.Do(request)
 
+		last := o.MaxAttempts != 0 && attempt+1 >= o.MaxAttempts
+
 		if reqErr != nil {
-			for _, handler := range o.ErrorHandlers {
-				err := handler(reqErr)
+			if last {
+				return resp, reqErr
+			}
 
-				if err != nil {
-					if errors.Is(err, ErrRetryRequest) {
-						continue
-					}
+			if retry, err := handleError(o.ErrorHandlers, reqErr); !retry {
+				return resp, err
+			}
+
+			continue
+		}
+
+		var err error
 
-					return resp, err
-				}
+		if !last {
+			var retry bool
+			if retry, err = handleResponse(o.ResponseHandlers, resp); retry {
+				utils.DClose(resp.Body)
+				continue
 			}
+		}
+
+		if err == nil && o.CheckStatus && !isSuccessful(resp.StatusCode) {
+			err = newHTTPStatusError(resp)
+		}
 
-			return resp, reqErr
+		if err != nil {
+			utils.DClose(resp.Body)
+			return resp, err
 		}
 
 		return resp, nil
 	}
 }
+
+// handleError passes the error to the handlers, retry is true if the request must be repeated, otherwise the error to
+// report is returned.
+func handleError(handlers []ErrorHandler, reqErr error) (retry bool, err error) {
+	for _, handler := range handlers {
+		if err := handler(reqErr); err != nil {
+			if errors.Is(err, ErrRetryRequest) {
+				return true, nil
+			}
+
+			return false, err
+		}
+	}
+
+	return false, reqErr
+}
+
+// handleResponse passes the response to the handlers, retry is true if the request must be repeated, otherwise the
+// error to report instead of the response is returned, if any.
+func handleResponse(handlers []ResponseHandler, resp *http.Response) (retry bool, err error) {
+	for _, handler := range handlers {
+		if err := handler(resp); err != nil {
+			if errors.Is(err, ErrRetryRequest) {
+				return true, nil
+			}
+
+			return false, err
+		}
+	}
+
+	return false, nil
+}
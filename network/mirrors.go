@@ -0,0 +1,127 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/brawaru/marct/globstate"
+	"github.com/brawaru/marct/utils"
+)
+
+// MirrorRule rewrites URLs starting with the prefix to start with each of the mirrors in turn.
+type MirrorRule struct {
+	Prefix     string   // Beginning of the URLs to rewrite.
+	Mirrors    []string // Replacements of the prefix, tried in order.
+	SkipOrigin bool     // Whether the original URL is not tried once all mirrors fail.
+}
+
+var mirrors struct {
+	sync.RWMutex
+	rules []MirrorRule
+}
+
+// SetMirrors replaces the rules used to rewrite URLs of all requests made with PerformRequest.
+func SetMirrors(rules []MirrorRule) {
+	mirrors.Lock()
+	defer mirrors.Unlock()
+
+	mirrors.rules = rules
+}
+
+// MirrorURLs returns URLs to try in order instead of the URL, according to the rule with the longest matching prefix.
+// If no rule matches, the URL itself is returned.
+func MirrorURLs(rawURL string) []string {
+	mirrors.RLock()
+	defer mirrors.RUnlock()
+
+	var rule *MirrorRule
+
+	for i, r := range mirrors.rules {
+		if strings.HasPrefix(rawURL, r.Prefix) && (rule == nil || len(r.Prefix) > len(rule.Prefix)) {
+			rule = &mirrors.rules[i]
+		}
+	}
+
+	if rule == nil {
+		return []string{rawURL}
+	}
+
+	rest := strings.TrimPrefix(rawURL, rule.Prefix)
+
+	urls := make([]string, 0, len(rule.Mirrors)+1)
+	for _, m := range rule.Mirrors {
+		urls = append(urls, m+rest)
+	}
+
+	if !rule.SkipOrigin || len(urls) == 0 {
+		urls = append(urls, rawURL)
+	}
+
+	return urls
+}
+
+// mirrorRequest copies the request to be sent to another URL.
+func mirrorRequest(request *http.Request, rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse %q as url: %w", rawURL, err)
+	}
+
+	r := request.Clone(request.Context())
+	r.URL = u
+	r.Host = ""
+
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("get body: %w", err)
+		}
+
+		r.Body = body
+	}
+
+	return r, nil
+}
+
+// performMirrored sends the request to each of the URLs in turn until one of them responds successfully. Each URL but
+// the last is tried once, so that failing mirror is not retried before falling back to the next one. The response or
+// error of the last URL is returned if all of them fail.
+func performMirrored(request *http.Request, urls []string, options ...Option) (resp *http.Response, err error) {
+	for i, u := range urls {
+		last := i == len(urls)-1
+
+		r, mirrorErr := mirrorRequest(request, u)
+		if mirrorErr != nil {
+			err = mirrorErr
+			continue
+		}
+
+		o := options
+		if !last {
+			o = append(append([]Option{}, options...), WithMaxAttempts(1))
+		}
+
+		resp, err = performRequest(r, o...)
+
+		if last || (err == nil && resp.StatusCode < http.StatusBadRequest) {
+			return
+		}
+
+		// printed to stderr, so that progress drawn on stdout is not broken
+		if err == nil {
+			utils.DClose(resp.Body)
+
+			if globstate.VerboseLogs {
+				_, _ = fmt.Fprintf(os.Stderr, "mirror %s responded with %s, trying next\n", u, resp.Status)
+			}
+		} else if globstate.VerboseLogs {
+			_, _ = fmt.Fprintf(os.Stderr, "mirror %s failed: %s, trying next\n", u, err)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,95 @@
+package network
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorURLs(t *testing.T) {
+	SetMirrors([]MirrorRule{
+		{Prefix: "https://libraries.minecraft.net/", Mirrors: []string{"https://a.example/maven/", "https://b.example/"}},
+		{Prefix: "https://libraries.minecraft.net/com/", Mirrors: []string{"https://c.example/"}, SkipOrigin: true},
+	})
+	defer SetMirrors(nil)
+
+	assert.Equal(t, []string{
+		"https://a.example/maven/org/lwjgl/lwjgl.jar",
+		"https://b.example/org/lwjgl/lwjgl.jar",
+		"https://libraries.minecraft.net/org/lwjgl/lwjgl.jar",
+	}, MirrorURLs("https://libraries.minecraft.net/org/lwjgl/lwjgl.jar"))
+
+	assert.Equal(t, []string{"https://c.example/mojang/authlib.jar"}, MirrorURLs("https://libraries.minecraft.net/com/mojang/authlib.jar"))
+
+	assert.Equal(t, []string{"https://example.com/"}, MirrorURLs("https://example.com/"))
+}
+
+func TestPerformRequestMirrors(t *testing.T) {
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	var requested string
+
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		_, _ = io.WriteString(w, "mirrored")
+	}))
+	defer working.Close()
+
+	SetMirrors([]MirrorRule{
+		{Prefix: "https://piston-data.mojang.com/", Mirrors: []string{broken.URL + "/", working.URL + "/data/"}, SkipOrigin: true},
+	})
+	defer SetMirrors(nil)
+
+	req, err := http.NewRequest(http.MethodGet, "https://piston-data.mojang.com/v1/objects/client.jar", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	resp, err := PerformRequest(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "mirrored", string(b))
+	assert.Equal(t, "/data/v1/objects/client.jar", requested)
+}
+
+func TestPerformRequestMirrorsNotRetried(t *testing.T) {
+	attempts := 0
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer working.Close()
+
+	SetMirrors([]MirrorRule{
+		{Prefix: "https://libraries.minecraft.net/", Mirrors: []string{broken.URL + "/", working.URL + "/"}, SkipOrigin: true},
+	})
+	defer SetMirrors(nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://libraries.minecraft.net/a.jar", nil)
+
+	resp, err := PerformRequest(req, WithRetries(WithConnectionChecker(nil)), WithStatusCheck())
+	if assert.NoError(t, err) {
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, 1, attempts, "mirror must not be retried before falling back")
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/brawaru/marct/network/concheck"
 	"github.com/brawaru/marct/network/concheck/mozchecker"
+	"github.com/brawaru/marct/utils/slices"
 )
 
 type RequestRetrierOptions struct {
@@ -21,6 +22,20 @@ type RequestRetrierOptions struct {
 	RetryDelayMax         time.Duration    // Maximum delay between retries. It cannot be less than RetryDelay.
 	ConnectionChecker     concheck.Checker // Network connection checker in case of network error. If nil, then connection is not checked.
 	Context               context.Context  // Context for the retrier.
+	RetryStatuses         []int            // Status codes of responses that are retried.
+	MaxStatusRetries      int              // Maximum number of retries of responses with retryable status, 0 means infinite.
+	RetryAfterMax         time.Duration    // Longest Retry-After that is honored, responses asking to wait longer are not retried.
+}
+
+// delay returns the delay before the retry.
+func (o *RequestRetrierOptions) delay(retries int) time.Duration {
+	delay := o.RetryDelay * time.Duration(math.Max(1, o.RetryDelayMultiplier*float64(retries)))
+
+	if delay > o.RetryDelayMax {
+		delay = o.RetryDelayMax
+	}
+
+	return delay
 }
 
 type RetrierOption func(*RequestRetrierOptions)
@@ -59,6 +74,53 @@ func WithRetrierContext(ctx context.Context) RetrierOption {
 	}
 }
 
+// WithRetryStatuses returns an option that sets status codes of responses that are retried.
+func WithRetryStatuses(statusCodes ...int) RetrierOption {
+	return func(options *RequestRetrierOptions) {
+		options.RetryStatuses = statusCodes
+	}
+}
+
+// WithMaxStatusRetries returns an option that limits how many times responses with retryable status are retried.
+func WithMaxStatusRetries(maxRetries int) RetrierOption {
+	return func(options *RequestRetrierOptions) {
+		options.MaxStatusRetries = maxRetries
+	}
+}
+
+// WithRetryAfterMax returns an option that sets the longest Retry-After that is honored.
+func WithRetryAfterMax(max time.Duration) RetrierOption {
+	return func(options *RequestRetrierOptions) {
+		options.RetryAfterMax = max
+	}
+}
+
+// sleep waits for the duration, unless the context is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// defaultRetryStatuses are the status codes of responses indicating that the server is temporarily unable to respond.
+var defaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
 var isNetworkReset = func(errno syscall.Errno) bool {
 	return errno == syscall.ECONNRESET
 }
@@ -88,8 +150,11 @@ func WithRetries(options ...RetrierOption) Option {
 		RetryDelay:            time.Second,
 		RetryDelayMultiplier:  2,
 		RetryDelayMax:         time.Minute,
-		ConnectionChecker:     mozchecker.NewMozChecker(),
+		ConnectionChecker:     mozchecker.NewMozCheckerWithTransport(tr),
 		Context:               nil,
+		RetryStatuses:         defaultRetryStatuses,
+		MaxStatusRetries:      5,
+		RetryAfterMax:         5 * time.Minute,
 	}
 
 	for _, option := range options {
@@ -103,11 +168,7 @@ func WithRetries(options ...RetrierOption) Option {
 			isNetErr := IsNetworkError(err)
 
 			if (o.AllowNonNetworkErrors || isNetErr) && (o.MaxRetries == 0 || retries < o.MaxRetries) {
-				delay := o.RetryDelay * time.Duration(math.Max(1, o.RetryDelayMultiplier*float64(retries)))
-
-				if delay > o.RetryDelayMax {
-					delay = o.RetryDelayMax
-				}
+				delay := o.delay(retries)
 
 				if isNetErr && o.ConnectionChecker != nil {
 					netCheckStart := time.Now()
@@ -138,5 +199,38 @@ func WithRetries(options ...RetrierOption) Option {
 
 			return nil // Pass error to the next handler
 		})
+
+		statusRetries := 0 // how many retries of responses we've done
+
+		options.ResponseHandlers = append(options.ResponseHandlers, func(resp *http.Response) error {
+			if !slices.Includes(o.RetryStatuses, resp.StatusCode) {
+				return nil // Pass response to the next handler
+			}
+
+			if o.MaxStatusRetries != 0 && statusRetries >= o.MaxStatusRetries {
+				return newHTTPStatusError(resp)
+			}
+
+			delay := o.delay(statusRetries)
+
+			if after := retryAfter(resp, time.Now()); after > o.RetryAfterMax {
+				return newHTTPStatusError(resp)
+			} else if after > delay {
+				delay = after
+			}
+
+			ctx := o.Context
+			if ctx == nil {
+				ctx = req.Context()
+			}
+
+			if err := sleep(ctx, delay); err != nil {
+				return err
+			}
+
+			statusRetries++
+
+			return ErrRetryRequest
+		})
 	}
 }
@@ -0,0 +1,117 @@
+package network
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxErrorBodySize is the maximum number of bytes of the response body kept in HTTPStatusError.
+const maxErrorBodySize = 512
+
+// HTTPStatusError is reported when the server responds with status other than 2xx.
+type HTTPStatusError struct {
+	StatusCode int    // Status code of the response.
+	Status     string // Status line of the response, e.g. "404 Not Found".
+	URL        string // URL of the request.
+	Body       string // Beginning of the response body.
+}
+
+func (e *HTTPStatusError) Error() string {
+	msg := fmt.Sprintf("%s responded with %s", e.URL, e.Status)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Is matches the error with the same status code, or any status code, if it is not set in target.
+func (e *HTTPStatusError) Is(target error) bool {
+	t, ok := target.(*HTTPStatusError)
+	return ok && (t.StatusCode == 0 || t.StatusCode == e.StatusCode)
+}
+
+// newHTTPStatusError creates the error from the response, reading the beginning of its body.
+func newHTTPStatusError(resp *http.Response) *HTTPStatusError {
+	e := &HTTPStatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+	}
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		e.URL = resp.Request.URL.String()
+	}
+
+	if resp.Body != nil {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize+1))
+		e.Body = truncateBody(b)
+	}
+
+	return e
+}
+
+// truncateBody converts the body to a single line that is at most maxErrorBodySize bytes long.
+func truncateBody(b []byte) string {
+	truncated := len(b) > maxErrorBodySize
+	if truncated {
+		b = b[:maxErrorBodySize]
+
+		// do not cut the last character in half
+		for len(b) > 0 && !utf8.Valid(b) {
+			b = b[:len(b)-1]
+		}
+	}
+
+	s := strings.Join(strings.Fields(string(b)), " ")
+	if truncated {
+		s += "…"
+	}
+
+	return s
+}
+
+func isSuccessful(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// WithStatusCheck returns an option that reports responses with status other than 2xx as HTTPStatusError, closing
+// their body.
+func WithStatusCheck() Option {
+	return func(_ *http.Request, options *ActionOptions) {
+		options.CheckStatus = true
+	}
+}
+
+// WithMaxAttempts returns an option that limits how many times the request is sent, including retries.
+func WithMaxAttempts(attempts int) Option {
+	return func(_ *http.Request, options *ActionOptions) {
+		options.MaxAttempts = attempts
+	}
+}
+
+// retryAfter parses the Retry-After header of the response, which is either a number of seconds or a date. Zero is
+// returned if the header is missing or invalid.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
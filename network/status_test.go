@@ -0,0 +1,143 @@
+package network
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetries() Option {
+	return WithRetries(WithConstRetryDelay(time.Millisecond), WithConnectionChecker(nil))
+}
+
+func TestRetryStatus(t *testing.T) {
+	attempts := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+
+	resp, err := PerformRequest(req, testRetries(), WithStatusCheck())
+	if assert.NoError(t, err) {
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Equal(t, 3, attempts)
+	}
+}
+
+func TestRetryStatusExhausted(t *testing.T) {
+	attempts := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+
+	_, err := PerformRequest(req, WithRetries(WithConstRetryDelay(time.Millisecond), WithConnectionChecker(nil), WithMaxStatusRetries(2)))
+
+	var statusErr *HTTPStatusError
+	if assert.True(t, errors.As(err, &statusErr)) {
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+		assert.Equal(t, srv.URL, statusErr.URL)
+		assert.Equal(t, "unavailable", statusErr.Body)
+	}
+
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	attempts := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+
+	_, err := PerformRequest(req, testRetries())
+
+	assert.ErrorIs(t, err, &HTTPStatusError{StatusCode: http.StatusServiceUnavailable})
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryBody(t *testing.T) {
+	var bodies []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	req, _ := PostRequest(srv.URL, "text/plain", strings.NewReader("payload"))
+
+	resp, err := PerformRequest(req, testRetries())
+	if assert.NoError(t, err) {
+		_ = resp.Body.Close()
+		assert.Equal(t, []string{"payload", "payload"}, bodies, "body must be sent again")
+	}
+}
+
+func TestDownloadStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, strings.Repeat("not found ", 100), http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "all.json")
+
+	_, err := Download(srv.URL, dest)
+
+	var statusErr *HTTPStatusError
+	if assert.True(t, errors.As(err, &statusErr)) {
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+		assert.LessOrEqual(t, len(statusErr.Body), maxErrorBodySize+len("…"))
+	}
+
+	assert.NoFileExists(t, dest, "error body must not be written")
+	assert.NoFileExists(t, PartName(dest))
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	resp := &http.Response{Header: http.Header{}}
+	assert.Equal(t, time.Duration(0), retryAfter(resp, now))
+
+	resp.Header.Set("Retry-After", "120")
+	assert.Equal(t, 2*time.Minute, retryAfter(resp, now))
+
+	resp.Header.Set("Retry-After", now.Add(30*time.Second).Format(http.TimeFormat))
+	assert.Equal(t, 30*time.Second, retryAfter(resp, now))
+
+	resp.Header.Set("Retry-After", "soon")
+	assert.Equal(t, time.Duration(0), retryAfter(resp, now))
+}
@@ -0,0 +1,128 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// TransportOptions configure the transport shared by all requests made with PerformRequest.
+type TransportOptions struct {
+	Proxy   *url.URL // Proxy to send requests through, including its credentials, nil to use the proxy from environment.
+	NoProxy []string // Hosts which are requested directly, a leading dot matches subdomains too, "*" matches all hosts.
+	RootCAs [][]byte // PEM-encoded certificates trusted in addition to the system ones.
+}
+
+// ErrNoCertificates is returned by ConfigureTransport when the bundle contains no certificates.
+var ErrNoCertificates = errors.New("no certificates found")
+
+// ConfigureTransport applies the options to the transport shared by all requests. It must be called before any request
+// is made.
+func ConfigureTransport(o TransportOptions) error {
+	tlsConfig := &tls.Config{
+		Renegotiation:      tls.RenegotiateOnceAsClient,
+		InsecureSkipVerify: false,
+	}
+
+	if len(o.RootCAs) != 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		for i, pem := range o.RootCAs {
+			if !pool.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("bundle %d: %w", i, ErrNoCertificates)
+			}
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	tr.TLSClientConfig = tlsConfig
+	tr.Proxy = proxyFunc(o.Proxy, o.NoProxy)
+	tr.CloseIdleConnections()
+
+	return nil
+}
+
+var lazyTransport struct {
+	sync.Mutex
+	configure func() error // Function configuring the transport, nil once it has been called.
+	err       error        // Error returned by the function.
+}
+
+// ConfigureTransportLazily sets the function configuring the transport, for example, with ConfigureTransport, which is
+// called once right before the first request made with PerformRequest. If the function fails, all requests fail with
+// its error. It allows to skip costly configuration, like reading the proxy password, when no requests are made.
+func ConfigureTransportLazily(configure func() error) {
+	lazyTransport.Lock()
+	defer lazyTransport.Unlock()
+
+	lazyTransport.configure = configure
+	lazyTransport.err = nil
+}
+
+// ensureTransport configures the transport with the function set by ConfigureTransportLazily, if it hasn't been yet.
+func ensureTransport() error {
+	lazyTransport.Lock()
+	defer lazyTransport.Unlock()
+
+	if lazyTransport.configure != nil {
+		lazyTransport.err = lazyTransport.configure()
+		lazyTransport.configure = nil
+	}
+
+	return lazyTransport.err
+}
+
+// proxyFunc returns the function choosing the proxy for the request.
+func proxyFunc(proxy *url.URL, noProxy []string) func(*http.Request) (*url.URL, error) {
+	if proxy == nil {
+		return http.ProxyFromEnvironment
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+
+		return proxy, nil
+	}
+}
+
+// bypassProxy checks whether the host should be requested directly.
+func bypassProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+
+	if host == "localhost" {
+		return true
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	for _, p := range noProxy {
+		p = strings.ToLower(p)
+
+		switch {
+		case p == "*":
+			return true
+		case strings.HasPrefix(p, "."):
+			if strings.HasSuffix(host, p) || host == p[1:] {
+				return true
+			}
+		case host == p:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,500 @@
+// Package archive extracts zip and tar archives, the latter optionally compressed with gzip or xz.
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/brawaru/marct/utils"
+	"github.com/brawaru/marct/utils/orderedmap"
+	"github.com/brawaru/marct/validfile"
+)
+
+// sanePath returns either a path relative to the destination directory, or an error if it escapes the boundaries of
+// the destination directory.
+func sanePath(name string, dest string) (string, error) {
+	dp := filepath.Join(dest, filepath.FromSlash(name))
+	if !strings.HasPrefix(dp, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal path: %s", name)
+	}
+	return dp, nil
+}
+
+// entryName normalises the name of the entry, so that "./a/b" of the archives made with "tar -C dir ." names the same
+// entry as "a/b". Names of directories keep their trailing slash.
+func entryName(name string) string {
+	n := path.Clean(strings.TrimPrefix(name, "./"))
+
+	if strings.HasSuffix(name, "/") && n != "." && n != "/" {
+		n += "/"
+	}
+
+	return n
+}
+
+// checkNoSymlinks returns an error if any existing component of the path between the destination directory and the
+// last element of dst is a symbolic link, so that nothing is written outside the destination directory through it.
+func checkNoSymlinks(dst string, dest string) error {
+	rel, err := filepath.Rel(dest, filepath.Dir(dst))
+	if err != nil {
+		return fmt.Errorf("illegal path: %s", dst)
+	}
+
+	if rel == "." {
+		return nil
+	}
+
+	p := dest
+	for _, c := range strings.Split(rel, string(os.PathSeparator)) {
+		p = filepath.Join(p, c)
+
+		stat, err := os.Lstat(p)
+		if err != nil {
+			if utils.DoesNotExist(err) {
+				return nil // neither it nor its children exist yet
+			}
+
+			return fmt.Errorf("stat %q: %w", p, err)
+		}
+
+		if stat.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("illegal path through symlink %q: %s", p, dst)
+		}
+	}
+
+	return nil
+}
+
+// resolveLink returns the path the link target points to once the links already extracted are followed.
+func resolveLink(dir string, linkname string) (string, error) {
+	p, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, c := range strings.Split(filepath.FromSlash(linkname), string(os.PathSeparator)) {
+		switch c {
+		case "", ".":
+			continue
+		case "..":
+			p = filepath.Dir(p)
+			continue
+		}
+
+		p = filepath.Join(p, c)
+
+		if stat, err := os.Lstat(p); err == nil && stat.Mode()&os.ModeSymlink != 0 {
+			if p, err = filepath.EvalSymlinks(p); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// ErrSkip is a special error that can be returned from EntryProcessor to skip the extraction of an entry.
+var ErrSkip = errors.New("skip")
+
+// AbortErr is a special error that can be returned to abort the extraction of an entry. It's prominent use is during
+// the validation of the file to signal critical errors unrelated to the validity of the file.
+type AbortErr struct {
+	Err error // Underlying error.
+}
+
+func (e *AbortErr) Error() string {
+	return fmt.Sprintf("abort: %s", e.Err.Error())
+}
+
+func (e *AbortErr) Unwrap() error {
+	return e.Err
+}
+
+func (e *AbortErr) Is(err error) bool {
+	t, ok := err.(*AbortErr)
+	return ok && errors.Is(t.Err, e.Err)
+}
+
+// EntryType is the type of the entry in the archive.
+type EntryType int
+
+const (
+	TypeFile    EntryType = iota // Regular file.
+	TypeDir                      // Directory.
+	TypeSymlink                  // Symbolic link, pointing to Linkname.
+	TypeLink                     // Hard link to the entry named Linkname.
+)
+
+// Entry is a file, directory or link in the archive.
+type Entry struct {
+	Name     string      // Name of the entry in the archive.
+	Type     EntryType   // Type of the entry.
+	Mode     fs.FileMode // Permission bits of the entry.
+	Linkname string      // Target of the link.
+	Size     int64       // Size of the file contents.
+
+	index int                           // Position of the entry in the archive.
+	open  func() (io.ReadCloser, error) // Opens the contents of the entry.
+}
+
+// Open opens the contents of the entry. For tar archives, the archive has to be read up to the entry, so the contents
+// should be read during the extraction where possible.
+func (e *Entry) Open() (io.ReadCloser, error) {
+	return e.open()
+}
+
+// EntryProcessor is a function that is called for each file in the archive before it is extracted, the arguments are
+// as follows: name - name of the file in the archive, f - the entry in the archive, dest - the destination (where the
+// file is extracted). The function may process file in any desired way or return ErrSkip to skip its extraction.
+type EntryProcessor func(e *Extractor, name string, f *Entry, dest string) error
+
+// DuplicateResolver is a function that is called when two files with the same name are found in the archive. The
+// function is called with the name of the file, the first file and the second file. The function may return the file
+// to use or an error.
+type DuplicateResolver func(e *Extractor, name string, a *Entry, b *Entry) (*Entry, error)
+
+// FileValidator is a function that is called for each extracted entry together with destination and entry
+// itself, it checks whether the extracted file is valid or not. If the file is invalid, it should return an error,
+// otherwise nil is expected. This function is never called for directories and links.
+type FileValidator func(e *Extractor, name string, dst string, f *os.File) error
+
+type ExtractorOptions struct {
+	Format            Format            // Format of the archive, detected from its contents if unknown.
+	EntryProcessor    EntryProcessor    // Function to process file before extraction.
+	DuplicateResolver DuplicateResolver // Function to resolve duplicate files.
+	FileValidator     FileValidator     // Function to validate existing or extracted files.
+}
+
+type Option func(*ExtractorOptions)
+
+// WithFormat returns an option that sets the format of the archive instead of detecting it.
+func WithFormat(f Format) Option {
+	return func(o *ExtractorOptions) {
+		o.Format = f
+	}
+}
+
+func WithEntryProcessor(f EntryProcessor) Option {
+	return func(o *ExtractorOptions) {
+		o.EntryProcessor = f
+	}
+}
+
+func WithDuplicateResolver(f DuplicateResolver) Option {
+	return func(o *ExtractorOptions) {
+		o.DuplicateResolver = f
+	}
+}
+
+// WithErrOnDuplicates returns an option that sets the DuplicateResolveFunc to a function that returns an error when
+// two files with the same name are found in the archive.
+func WithErrOnDuplicates() Option {
+	return func(o *ExtractorOptions) {
+		o.DuplicateResolver = func(_ *Extractor, name string, a *Entry, b *Entry) (*Entry, error) {
+			return nil, fmt.Errorf("duplicate file: %s", name)
+		}
+	}
+}
+
+// WithFileValidator returns an option that sets the FileValidator to the given function.
+func WithFileValidator(f FileValidator) Option {
+	return func(o *ExtractorOptions) {
+		o.FileValidator = f
+	}
+}
+
+type Extractor struct {
+	reader  reader                         // Backend reading the archive.
+	options *ExtractorOptions              // Extractor options.
+	Entries orderedmap.Map[string, *Entry] // Map of files in the archive.
+}
+
+func (e *Extractor) indexFiles() error {
+	entries, err := e.reader.list()
+	if err != nil {
+		return err
+	}
+
+	m := orderedmap.New[string, *Entry]()
+
+	for _, f := range entries {
+		if m.HasKey(f.Name) {
+			a := m.Get(f.Name)
+
+			if e.options.DuplicateResolver != nil {
+				chosen, err := e.options.DuplicateResolver(e, f.Name, a, f)
+				if err != nil {
+					return err
+				}
+				f = chosen
+			} else {
+				f = nil
+			}
+
+			if f == nil {
+				continue
+			}
+		}
+
+		m.Put(f.Name, f)
+	}
+
+	e.Entries = m
+
+	return nil
+}
+
+func (e *Extractor) init(name string) error {
+	format := e.options.Format
+	if format == FormatUnknown {
+		detected, err := DetectFormat(name)
+		if err != nil {
+			return fmt.Errorf("detect format of %q: %w", name, err)
+		}
+		format = detected
+	}
+
+	r, err := openReader(name, format)
+	if err != nil {
+		return fmt.Errorf("open %q: %w", name, err)
+	}
+	e.reader = r
+
+	if err := e.indexFiles(); err != nil {
+		return fmt.Errorf("index files: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Extractor) Close() error {
+	if e.reader == nil {
+		return nil
+	}
+
+	return e.reader.Close()
+}
+
+// NewExtractor opens the archive for extraction. Format of the archive is detected from its contents, unless set with
+// WithFormat option.
+func NewExtractor(name string, opts ...Option) (*Extractor, error) {
+	e := &Extractor{
+		options: &ExtractorOptions{},
+	}
+
+	for _, opt := range opts {
+		opt(e.options)
+	}
+
+	err := e.init(name)
+
+	return e, err
+}
+
+// mkdirParent creates parent directories of the entry, using the mode of the parent entry if the archive has one.
+// Parent directories must not be symbolic links.
+func (e *Extractor) mkdirParent(f *Entry, dst string, dest string) error {
+	if err := checkNoSymlinks(dst, dest); err != nil {
+		return err
+	}
+
+	fDir := filepath.Dir(dst)
+	if exists, err := validfile.DirExists(fDir); err != nil {
+		return fmt.Errorf("check dir %q: %w", fDir, err)
+	} else if exists {
+		return nil
+	}
+
+	dirMode := fs.FileMode(0777)
+
+	dirName := path.Dir(strings.TrimSuffix(f.Name, "/")) + "/"
+	if e.Entries.HasKey(dirName) {
+		dirMode = e.Entries.Get(dirName).Mode | 0700
+	}
+
+	if err := os.MkdirAll(fDir, dirMode); err != nil {
+		return fmt.Errorf("mkdir %q: %w", fDir, err)
+	}
+
+	return nil
+}
+
+// writeLink creates symbolic or hard link of the entry. Links cannot point outside of the destination directory.
+func (e *Extractor) writeLink(f *Entry, dst string, dest string) error {
+	var target string
+
+	if f.Type == TypeSymlink {
+		if filepath.IsAbs(f.Linkname) || path.IsAbs(f.Linkname) {
+			return fmt.Errorf("illegal link target: %s", f.Linkname)
+		}
+
+		// links extracted before may lead the target out of the destination directory
+		realDest, err := filepath.EvalSymlinks(dest)
+		if err != nil {
+			return fmt.Errorf("resolve %q: %w", dest, err)
+		}
+
+		resolved, err := resolveLink(filepath.Dir(dst), f.Linkname)
+		if err != nil {
+			return fmt.Errorf("resolve link target %s: %w", f.Linkname, err)
+		}
+
+		rel, err := filepath.Rel(realDest, resolved)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal link target: %s", f.Linkname)
+		}
+	} else {
+		t, err := sanePath(f.Linkname, dest)
+		if err != nil {
+			return err
+		}
+
+		if err := checkNoSymlinks(t, dest); err != nil {
+			return err
+		}
+
+		target = t
+	}
+
+	if err := os.Remove(dst); err != nil && !utils.DoesNotExist(err) {
+		return fmt.Errorf("remove %q: %w", dst, err)
+	}
+
+	if f.Type == TypeSymlink {
+		if err := os.Symlink(filepath.FromSlash(f.Linkname), dst); err != nil {
+			return fmt.Errorf("symlink %q: %w", dst, err)
+		}
+	} else if err := os.Link(target, dst); err != nil {
+		return fmt.Errorf("link %q: %w", dst, err)
+	}
+
+	return nil
+}
+
+func (e *Extractor) writeEntryDst(f *Entry, r io.Reader, dst string, dest string) error {
+	// If it's a directory, create it and return.
+	if f.Type == TypeDir {
+		if err := checkNoSymlinks(dst, dest); err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(dst, f.Mode|0700); err != nil {
+			return fmt.Errorf("mkdir %q: %w", dst, err)
+		}
+
+		return nil
+	}
+
+	// Create all parent directories.
+	if err := e.mkdirParent(f, dst, dest); err != nil {
+		return err
+	}
+
+	if f.Type != TypeFile {
+		return e.writeLink(f, dst, dest)
+	}
+
+	// the file is replaced rather than written through the link
+	if stat, err := os.Lstat(dst); err == nil && stat.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(dst); err != nil {
+			return fmt.Errorf("remove %q: %w", dst, err)
+		}
+	}
+
+	if exists, err := validfile.FileExists(dst); err == nil {
+		if exists && e.options.FileValidator != nil {
+			if err := e.options.FileValidator(e, f.Name, dst, nil); err == nil {
+				return nil // the file is valid
+			} else if errors.Is(err, &AbortErr{}) {
+				return err
+			}
+		}
+	} else {
+		return fmt.Errorf("exists %q: %w", dst, err)
+	}
+
+	outputFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode)
+	if err != nil {
+		return fmt.Errorf("open %q: %w", dst, err)
+	}
+	defer utils.DClose(outputFile)
+
+	if _, err := io.Copy(outputFile, r); err != nil {
+		return fmt.Errorf("write %q: %w", dst, err)
+	}
+
+	// mode of the existing file is not changed by OpenFile
+	if err := outputFile.Chmod(f.Mode); err != nil {
+		return fmt.Errorf("chmod %q: %w", dst, err)
+	}
+
+	return outputFile.Sync()
+}
+
+func (e *Extractor) extractEntry(f *Entry, r io.Reader, dest string) error {
+	if filepath.Join(dest, filepath.FromSlash(f.Name)) == filepath.Clean(dest) {
+		return nil // the destination directory itself, such as "./" entry
+	}
+
+	dst, err := sanePath(f.Name, dest)
+	if err != nil {
+		return err
+	}
+
+	if err := e.writeEntryDst(f, r, dst, dest); err != nil {
+		return err
+	}
+
+	if f.Type == TypeFile && e.options.FileValidator != nil {
+		if err := e.options.FileValidator(e, f.Name, dst, nil); err != nil {
+			return fmt.Errorf("post-validate %q: %w", dst, err)
+		}
+	}
+
+	return nil
+}
+
+// Extract extracts entries of the archive to the destination directory in the order they appear in the archive.
+func (e *Extractor) Extract(dest string) error {
+	return e.reader.walk(func(v *Entry, r io.Reader) error {
+		k := v.Name
+
+		// entry is a duplicate that has not been chosen by the resolver
+		if chosen := e.Entries.Get(k); chosen == nil || chosen.index != v.index {
+			return nil
+		}
+
+		if e.options.EntryProcessor != nil {
+			if err := e.options.EntryProcessor(e, k, v, dest); err != nil {
+				if errors.Is(err, ErrSkip) {
+					return nil
+				}
+
+				return fmt.Errorf("process file %q: %w", k, err)
+			}
+		}
+
+		if err := e.extractEntry(v, r, dest); err != nil {
+			return fmt.Errorf("extract %q: %w", k, err)
+		}
+
+		return nil
+	})
+}
+
+// Extract extracts the archive to the destination directory.
+func Extract(name string, dest string, opts ...Option) error {
+	e, err := NewExtractor(name, opts...)
+	if err != nil {
+		return err
+	}
+	defer utils.DClose(e)
+
+	return e.Extract(dest)
+}
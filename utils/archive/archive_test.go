@@ -0,0 +1,219 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ulikunitz/xz"
+)
+
+type testEntry struct {
+	name     string
+	mode     os.FileMode
+	body     string
+	linkname string
+}
+
+var testEntries = []testEntry{
+	{name: "jre/", mode: os.ModeDir | 0755},
+	{name: "jre/bin/java", mode: 0755, body: "#!/bin/sh\n"},
+	{name: "jre/release", mode: 0644, body: "JAVA_VERSION=17"},
+	{name: "jre/bin/jre", mode: os.ModeSymlink | 0777, linkname: "java"},
+}
+
+func writeTar(t *testing.T, w io.Writer, entries []testEntry) {
+	tw := tar.NewWriter(w)
+
+	for _, e := range entries {
+		h := &tar.Header{Name: e.name, Mode: int64(e.mode.Perm()), Size: int64(len(e.body))}
+
+		switch {
+		case e.mode.IsDir():
+			h.Typeflag = tar.TypeDir
+		case e.mode&os.ModeSymlink != 0:
+			h.Typeflag = tar.TypeSymlink
+			h.Linkname = e.linkname
+		default:
+			h.Typeflag = tar.TypeReg
+		}
+
+		if !assert.NoError(t, tw.WriteHeader(h)) {
+			t.FailNow()
+		}
+
+		_, _ = io.WriteString(tw, e.body)
+	}
+
+	assert.NoError(t, tw.Close())
+}
+
+func writeZip(t *testing.T, w io.Writer, entries []testEntry) {
+	zw := zip.NewWriter(w)
+
+	for _, e := range entries {
+		h := &zip.FileHeader{Name: e.name}
+		h.SetMode(e.mode)
+
+		f, err := zw.CreateHeader(h)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		if e.mode&os.ModeSymlink != 0 {
+			_, _ = io.WriteString(f, e.linkname)
+		} else {
+			_, _ = io.WriteString(f, e.body)
+		}
+	}
+
+	assert.NoError(t, zw.Close())
+}
+
+func createArchive(t *testing.T, name string, entries []testEntry) string {
+	var buf bytes.Buffer
+
+	switch FormatByName(name) {
+	case FormatZip:
+		writeZip(t, &buf, entries)
+	case FormatTar:
+		writeTar(t, &buf, entries)
+	case FormatTarGzip:
+		gz := gzip.NewWriter(&buf)
+		writeTar(t, gz, entries)
+		assert.NoError(t, gz.Close())
+	case FormatTarXz:
+		xzw, err := xz.NewWriter(&buf)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		writeTar(t, xzw, entries)
+		assert.NoError(t, xzw.Close())
+	}
+
+	p := filepath.Join(t.TempDir(), name)
+	if !assert.NoError(t, os.WriteFile(p, buf.Bytes(), 0644)) {
+		t.FailNow()
+	}
+
+	return p
+}
+
+func TestExtract(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links and permissions are not supported")
+	}
+
+	for _, name := range []string{"a.zip", "a.tar", "a.tar.gz", "a.tar.xz"} {
+		t.Run(name, func(t *testing.T) {
+			p := createArchive(t, name, testEntries)
+
+			format, err := DetectFormat(p)
+			if assert.NoError(t, err) {
+				assert.Equal(t, FormatByName(name), format)
+			}
+
+			dest := t.TempDir()
+			if !assert.NoError(t, Extract(p, dest)) {
+				return
+			}
+
+			if info, err := os.Stat(filepath.Join(dest, "jre", "bin", "java")); assert.NoError(t, err) {
+				assert.Equal(t, os.FileMode(0755), info.Mode().Perm(), "executable bit must be preserved")
+			}
+
+			if b, err := os.ReadFile(filepath.Join(dest, "jre", "release")); assert.NoError(t, err) {
+				assert.Equal(t, "JAVA_VERSION=17", string(b))
+			}
+
+			if link, err := os.Readlink(filepath.Join(dest, "jre", "bin", "jre")); assert.NoError(t, err) {
+				assert.Equal(t, "java", link)
+			}
+		})
+	}
+}
+
+func TestExtractProcessor(t *testing.T) {
+	p := createArchive(t, "a.tar.gz", testEntries)
+	dest := t.TempDir()
+
+	var validated []string
+
+	err := Extract(p, dest, WithEntryProcessor(func(e *Extractor, name string, f *Entry, dest string) error {
+		if f.Type == TypeSymlink {
+			return ErrSkip
+		}
+
+		return nil
+	}), WithFileValidator(func(e *Extractor, name string, dst string, f *os.File) error {
+		validated = append(validated, name)
+
+		rc, err := e.Entries.Get("jre/release").Open()
+		if err != nil {
+			return &AbortErr{Err: err}
+		}
+
+		defer rc.Close()
+
+		b, err := io.ReadAll(rc)
+		if err != nil || string(b) != "JAVA_VERSION=17" {
+			return &AbortErr{Err: err}
+		}
+
+		return nil
+	}))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"jre/bin/java", "jre/release"}, validated)
+		assert.NoFileExists(t, filepath.Join(dest, "jre", "bin", "jre"))
+	}
+}
+
+func TestExtractDotEntries(t *testing.T) {
+	// as made by "tar -C dir -czf a.tar.gz ."
+	p := createArchive(t, "a.tar.gz", []testEntry{
+		{name: "./", mode: os.ModeDir | 0755},
+		{name: "./mods/", mode: os.ModeDir | 0755},
+		{name: "./mods/a.jar", mode: 0644, body: "mod"},
+	})
+
+	dest := t.TempDir()
+	if !assert.NoError(t, Extract(p, dest)) {
+		return
+	}
+
+	if b, err := os.ReadFile(filepath.Join(dest, "mods", "a.jar")); assert.NoError(t, err) {
+		assert.Equal(t, "mod", string(b))
+	}
+}
+
+func TestExtractTraversal(t *testing.T) {
+	for _, entries := range [][]testEntry{
+		{{name: "../evil", mode: 0644, body: "evil"}},
+		{{name: "jre/evil", mode: os.ModeSymlink | 0777, linkname: "../../evil"}},
+		{
+			{name: "x", mode: os.ModeSymlink | 0777, linkname: "."},
+			{name: "x/l", mode: os.ModeSymlink | 0777, linkname: ".."},
+			{name: "l/evil", mode: 0644, body: "evil"},
+		},
+		{
+			{name: "a/", mode: os.ModeDir | 0755},
+			{name: "a/x", mode: os.ModeSymlink | 0777, linkname: "."},
+			{name: "a/l", mode: os.ModeSymlink | 0777, linkname: "x/../.."},
+			{name: "a/l/evil", mode: 0644, body: "evil"},
+		},
+	} {
+		p := createArchive(t, "a.tar", entries)
+		dest := filepath.Join(t.TempDir(), "dest")
+
+		assert.Error(t, Extract(p, dest))
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(dest), "evil"))
+	}
+}
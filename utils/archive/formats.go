@@ -0,0 +1,355 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/brawaru/marct/utils"
+	"github.com/ulikunitz/xz"
+)
+
+// Format is the format of the archive.
+type Format int
+
+const (
+	FormatUnknown Format = iota // Format could not be determined.
+	FormatZip                   // Zip archive, including jar files.
+	FormatTar                   // Uncompressed tar archive.
+	FormatTarGzip               // Tar archive compressed with gzip.
+	FormatTarXz                 // Tar archive compressed with xz.
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatZip:
+		return "zip"
+	case FormatTar:
+		return "tar"
+	case FormatTarGzip:
+		return "tar.gz"
+	case FormatTarXz:
+		return "tar.xz"
+	default:
+		return "unknown"
+	}
+}
+
+// FormatByName guesses the format of the archive from the extension of its name.
+func FormatByName(name string) Format {
+	name = strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(name, ".zip"), strings.HasSuffix(name, ".jar"):
+		return FormatZip
+	case strings.HasSuffix(name, ".tar"):
+		return FormatTar
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return FormatTarGzip
+	case strings.HasSuffix(name, ".tar.xz"), strings.HasSuffix(name, ".txz"):
+		return FormatTarXz
+	default:
+		return FormatUnknown
+	}
+}
+
+var (
+	zipMagic  = []byte("PK\x03\x04")
+	gzipMagic = []byte{0x1f, 0x8b}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	tarMagic  = []byte("ustar")
+)
+
+// tarMagicOffset is the offset of the magic in the header of tar archive.
+const tarMagicOffset = 257
+
+// DetectFormat determines the format of the archive from its first bytes. Compressed streams are assumed to contain
+// tar archives.
+func DetectFormat(name string) (Format, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return FormatUnknown, err
+	}
+
+	defer utils.DClose(f)
+
+	header := make([]byte, tarMagicOffset+len(tarMagic))
+
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return FormatUnknown, fmt.Errorf("read %q: %w", name, err)
+	}
+
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, zipMagic):
+		return FormatZip, nil
+	case bytes.HasPrefix(header, gzipMagic):
+		return FormatTarGzip, nil
+	case bytes.HasPrefix(header, xzMagic):
+		return FormatTarXz, nil
+	case len(header) >= tarMagicOffset+len(tarMagic) && bytes.Equal(header[tarMagicOffset:], tarMagic):
+		return FormatTar, nil
+	default:
+		return FormatUnknown, nil
+	}
+}
+
+// reader is a backend reading the archive of a certain format.
+type reader interface {
+	io.Closer
+	// list returns all entries of the archive in the order they appear in it.
+	list() ([]*Entry, error)
+	// walk calls fn for each entry of the archive in the order they appear in it, together with the reader of its
+	// contents, which is only valid until fn returns.
+	walk(fn func(e *Entry, r io.Reader) error) error
+}
+
+func openReader(name string, format Format) (reader, error) {
+	switch format {
+	case FormatZip:
+		r, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &zipReader{reader: r}, nil
+	case FormatTar, FormatTarGzip, FormatTarXz:
+		return &tarReader{name: name, format: format}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", format)
+	}
+}
+
+// zipReader reads zip archives, which allow random access to the entries.
+type zipReader struct {
+	reader *zip.ReadCloser
+}
+
+func (z *zipReader) Close() error {
+	return z.reader.Close()
+}
+
+func (z *zipReader) list() ([]*Entry, error) {
+	entries := make([]*Entry, 0, len(z.reader.File))
+
+	for i, f := range z.reader.File {
+		f := f
+		mode := f.Mode()
+
+		e := &Entry{
+			Name:  entryName(f.Name),
+			Mode:  mode.Perm(),
+			Size:  int64(f.UncompressedSize64),
+			index: i,
+			open: func() (io.ReadCloser, error) {
+				return f.Open()
+			},
+		}
+
+		switch {
+		case mode.IsDir():
+			e.Type = TypeDir
+		case mode&fs.ModeSymlink != 0:
+			// target of the link is stored as the contents of the entry
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("open %q: %w", f.Name, err)
+			}
+
+			target, err := io.ReadAll(rc)
+			utils.DClose(rc)
+
+			if err != nil {
+				return nil, fmt.Errorf("read %q: %w", f.Name, err)
+			}
+
+			e.Type = TypeSymlink
+			e.Linkname = string(target)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func (z *zipReader) walk(fn func(e *Entry, r io.Reader) error) error {
+	entries, err := z.list()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := z.walkEntry(e, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (z *zipReader) walkEntry(e *Entry, fn func(e *Entry, r io.Reader) error) error {
+	if e.Type != TypeFile {
+		return fn(e, bytes.NewReader(nil))
+	}
+
+	rc, err := e.Open()
+	if err != nil {
+		return fmt.Errorf("open %q: %w", e.Name, err)
+	}
+
+	defer utils.DClose(rc)
+
+	return fn(e, rc)
+}
+
+// tarReader reads tar archives, optionally compressed. Such archives can only be read sequentially, so each pass over
+// the archive opens the file anew.
+type tarReader struct {
+	name   string
+	format Format
+}
+
+func (t *tarReader) Close() error {
+	return nil
+}
+
+// open opens the archive for a new pass, returned closer must be closed once the pass is complete.
+func (t *tarReader) open() (*tar.Reader, io.Closer, error) {
+	f, err := os.Open(t.name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var r io.Reader = f
+
+	switch t.format {
+	case FormatTarGzip:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			utils.DClose(f)
+			return nil, nil, fmt.Errorf("read gzip: %w", err)
+		}
+
+		r = gz
+	case FormatTarXz:
+		xzr, err := xz.NewReader(f)
+		if err != nil {
+			utils.DClose(f)
+			return nil, nil, fmt.Errorf("read xz: %w", err)
+		}
+
+		r = xzr
+	}
+
+	return tar.NewReader(r), f, nil
+}
+
+// tarEntryType maps the type of tar entry, ok is false for entries that are not extracted, like devices.
+func tarEntryType(flag byte) (EntryType, bool) {
+	switch flag {
+	case tar.TypeReg:
+		return TypeFile, true
+	case tar.TypeDir:
+		return TypeDir, true
+	case tar.TypeSymlink:
+		return TypeSymlink, true
+	case tar.TypeLink:
+		return TypeLink, true
+	default:
+		return 0, false
+	}
+}
+
+func (t *tarReader) walk(fn func(e *Entry, r io.Reader) error) error {
+	tr, c, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	defer utils.DClose(c)
+
+	for i := 0; ; i++ {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("read %q: %w", t.name, err)
+		}
+
+		typ, ok := tarEntryType(h.Typeflag)
+		if !ok {
+			continue
+		}
+
+		index := i
+
+		e := &Entry{
+			Name:     entryName(h.Name),
+			Type:     typ,
+			Mode:     fs.FileMode(h.Mode).Perm(),
+			Linkname: h.Linkname,
+			Size:     h.Size,
+			index:    index,
+			open: func() (io.ReadCloser, error) {
+				return t.openEntry(index)
+			},
+		}
+
+		if err := fn(e, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (t *tarReader) list() ([]*Entry, error) {
+	var entries []*Entry
+
+	err := t.walk(func(e *Entry, _ io.Reader) error {
+		entries = append(entries, e)
+		return nil
+	})
+
+	return entries, err
+}
+
+// entryReadCloser reads the entry of tar archive and closes the archive once done.
+type entryReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// openEntry opens the archive and reads it up to the entry with the index.
+func (t *tarReader) openEntry(index int) (io.ReadCloser, error) {
+	tr, c, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; ; i++ {
+		if _, err := tr.Next(); err != nil {
+			utils.DClose(c)
+
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("entry %d: %w", index, fs.ErrNotExist)
+			}
+
+			return nil, err
+		}
+
+		if i == index {
+			return &entryReadCloser{Reader: tr, Closer: c}, nil
+		}
+	}
+}